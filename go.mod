@@ -1,6 +1,6 @@
 module github.com/pree-dew/tree
 
-go 1.21.11
+go 1.23
 
 require github.com/stretchr/testify v1.9.0
 
@@ -0,0 +1,253 @@
+package ntree
+
+import (
+	"cmp"
+	"math"
+)
+
+// FoldUntil folds the elements of t in ascending key order into an
+// accumulator starting at init. The fold stops as soon as fn returns
+// false, and the accumulator returned by that call is the result.
+func FoldUntil[K comparable, V, A any](t *Tree[K, V], init A, fn func(acc A, key K, value V) (A, bool)) A {
+	acc := init
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		var more bool
+		acc, more = fn(acc, e.Key, e.Value)
+		return more
+	})
+
+	return acc
+}
+
+// PrefixSums returns, in ascending key order, the running total of the
+// values up to and including each element.
+func PrefixSums[K comparable](t *Tree[K, int]) []int {
+	sums := make([]int, 0, t.Size())
+	sum := 0
+	t.walk(t.Root, func(e *Element[K, int]) bool {
+		sum += e.Value
+		sums = append(sums, sum)
+		return true
+	})
+
+	return sums
+}
+
+// LongestNonDecreasingValueRun scans t in ascending key order and returns
+// the key that starts the longest run of values where each value is >= the
+// previous one, and the length of that run. The earliest run wins a tie.
+// An empty tree returns the zero key and a length of 0.
+func LongestNonDecreasingValueRun[K comparable](t *Tree[K, int]) (startKey K, length int) {
+	var runStart K
+	var prev int
+	run := 0
+	t.walk(t.Root, func(e *Element[K, int]) bool {
+		if run == 0 || e.Value < prev {
+			runStart, run = e.Key, 0
+		}
+
+		run++
+		prev = e.Value
+		if run > length {
+			startKey, length = runStart, run
+		}
+		return true
+	})
+
+	return startKey, length
+}
+
+// RollingAggregate returns, for each element in ascending key order, agg
+// applied to the values of the last window elements up to and including
+// it. Positions with fewer than window predecessors aggregate what is
+// available. The slice passed to agg must not be retained.
+func RollingAggregate[K comparable](t *Tree[K, int], window int, agg func([]int) int) []int {
+	if window < 1 {
+		window = 1
+	}
+
+	values := make([]int, 0, t.Size())
+	results := make([]int, 0, t.Size())
+	t.walk(t.Root, func(e *Element[K, int]) bool {
+		values = append(values, e.Value)
+		results = append(results, agg(values[max(0, len(values)-window):]))
+		return true
+	})
+
+	return results
+}
+
+// CountValueInversions returns the number of element pairs i < j, in
+// ascending key order, whose values are out of order: value[i] > value[j].
+// It runs a merge sort over the values in O(n log n).
+func CountValueInversions[K comparable](t *Tree[K, int]) int {
+	values := make([]int, 0, t.Size())
+	t.walk(t.Root, func(e *Element[K, int]) bool {
+		values = append(values, e.Value)
+		return true
+	})
+
+	return countInversions(values, make([]int, len(values)))
+}
+
+// countInversions sorts values in place, using buf as scratch space, and
+// returns the number of inversions it had to undo.
+func countInversions(values, buf []int) int {
+	if len(values) < 2 {
+		return 0
+	}
+
+	mid := len(values) / 2
+	count := countInversions(values[:mid], buf[:mid]) + countInversions(values[mid:], buf[mid:])
+
+	i, j, k := 0, mid, 0
+	for i < mid && j < len(values) {
+		if values[i] <= values[j] {
+			buf[k] = values[i]
+			i++
+		} else {
+			// every remaining left value is greater than values[j]
+			buf[k] = values[j]
+			count += mid - i
+			j++
+		}
+		k++
+	}
+
+	k += copy(buf[k:], values[i:mid])
+	copy(buf[k:], values[j:])
+	copy(values, buf)
+
+	return count
+}
+
+// MinByValue returns the element of t with the smallest value, preferring
+// the smallest key among equal values. found is false for an empty tree.
+func MinByValue[K comparable, V cmp.Ordered](t *Tree[K, V]) (key K, value V, found bool) {
+	return extremeByValue(t, func(v, best V) bool { return v < best })
+}
+
+// MaxByValue returns the element of t with the largest value, preferring
+// the smallest key among equal values. found is false for an empty tree.
+func MaxByValue[K comparable, V cmp.Ordered](t *Tree[K, V]) (key K, value V, found bool) {
+	return extremeByValue(t, func(v, best V) bool { return v > best })
+}
+
+// extremeByValue scans t in key order and keeps the first element whose
+// value beats every earlier one according to better.
+func extremeByValue[K comparable, V cmp.Ordered](t *Tree[K, V], better func(v, best V) bool) (key K, value V, found bool) {
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		if !found || better(e.Value, value) {
+			key, value, found = e.Key, e.Value, true
+		}
+		return true
+	})
+
+	return key, value, found
+}
+
+// number is the set of value types whose distance can be measured by
+// subtraction.
+type number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// distance returns |a-b| as a uint64 that orders like the true distance.
+// Subtracting in V could overflow, so integer gaps are taken in uint64,
+// where the difference of two sign-extended values is exact. Float gaps
+// are taken in float64, whose bit patterns order like the values for
+// non-negative numbers.
+func distance[V number](a, b V) uint64 {
+	if b < a {
+		a, b = b, a
+	}
+
+	if V(1)/2 != 0 {
+		return math.Float64bits(float64(b) - float64(a))
+	}
+
+	return uint64(b) - uint64(a)
+}
+
+// NearestByValue returns the element of t whose value is closest to
+// target, preferring the smallest key among equally close values. It scans
+// every element. found is false for an empty tree.
+func NearestByValue[K comparable, V number](t *Tree[K, V], target V) (key K, value V, found bool) {
+	var best uint64
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		if dist := distance(e.Value, target); !found || dist < best {
+			key, value, best, found = e.Key, e.Value, dist, true
+		}
+		return true
+	})
+
+	return key, value, found
+}
+
+// GroupBy groups the keys of t by groupFn, returning for each group its
+// keys in ascending order.
+func GroupBy[K comparable, V any, G comparable](t *Tree[K, V], groupFn func(K, V) G) map[G][]K {
+	groups := make(map[G][]K)
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		g := groupFn(e.Key, e.Value)
+		groups[g] = append(groups[g], e.Key)
+		return true
+	})
+
+	return groups
+}
+
+// InvertByValue returns a tree of the same order keyed by the values of t,
+// mapping each value to the keys that held it in ascending order.
+func InvertByValue[K comparable, V cmp.Ordered](t *Tree[K, V]) *Tree[V, []K] {
+	inverted := New[V, []K](t.m)
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		keys, _ := inverted.Get(e.Value)
+		inverted.Put(e.Value, append(keys, e.Key))
+		return true
+	})
+
+	return inverted
+}
+
+// Map returns a tree with the same keys, order and comparator as t and
+// each value replaced by fn(key, value). The result copies the shape of t
+// node for node instead of inserting, and fn is called in ascending key
+// order.
+func Map[K comparable, V, W any](t *Tree[K, V], fn func(K, V) W) *Tree[K, W] {
+	return &Tree[K, W]{
+		Root:       mapNode(t.Root, nil, fn),
+		Comparator: t.Comparator,
+		size:       t.size,
+		m:          t.m,
+	}
+}
+
+func mapNode[K comparable, V, W any](n *Node[K, V], parent *Node[K, W], fn func(K, V) W) *Node[K, W] {
+	if n == nil {
+		return nil
+	}
+
+	c := &Node[K, W]{
+		Parent:      parent,
+		Elements:    make([]*Element[K, W], len(n.Elements)),
+		subtreeSize: n.subtreeSize,
+	}
+	if len(n.Children) > 0 {
+		c.Children = make([]*Node[K, W], len(n.Children))
+	}
+
+	for i, e := range n.Elements {
+		if len(n.Children) > 0 {
+			c.Children[i] = mapNode(n.Children[i], c, fn)
+		}
+		c.Elements[i] = &Element[K, W]{Key: e.Key, Value: fn(e.Key, e.Value)}
+	}
+	if len(n.Children) > 0 {
+		c.Children[len(n.Elements)] = mapNode(n.Children[len(n.Elements)], c, fn)
+	}
+
+	return c
+}
@@ -0,0 +1,200 @@
+package ntree
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoldUntil(t *testing.T) {
+	tr := exampleTree()
+
+	calls := 0
+	sum := FoldUntil(tr, 0, func(acc int, key int, _ string) (int, bool) {
+		calls++
+		acc += key
+		return acc, acc <= 10
+	})
+	assert.Equal(t, 15, sum, "fold should stop once the sum exceeds 10")
+	assert.Equal(t, 5, calls, "fold should stop early")
+}
+
+func TestPrefixSums(t *testing.T) {
+	tr := New[string, int](3)
+	tr.Put("a", 1)
+	tr.Put("b", 2)
+	tr.Put("c", 3)
+
+	assert.Equal(t, []int{1, 3, 6}, PrefixSums(tr), "prefix sums should accumulate in key order")
+	assert.Empty(t, PrefixSums(New[string, int](3)), "empty tree has no sums")
+}
+
+func TestLongestNonDecreasingValueRun(t *testing.T) {
+	tr := New[int, int](3)
+	for i, v := range []int{3, 1, 2, 5, 4} {
+		tr.Put(i+1, v)
+	}
+
+	start, length := LongestNonDecreasingValueRun(tr)
+	assert.Equal(t, 2, start, "run 1,2,5 starts at key 2")
+	assert.Equal(t, 3, length, "run 1,2,5 has length 3")
+
+	start, length = LongestNonDecreasingValueRun(New[int, int](3))
+	assert.Equal(t, 0, start, "empty tree returns the zero key")
+	assert.Equal(t, 0, length, "empty tree has no run")
+}
+
+func TestRollingAggregate(t *testing.T) {
+	tr := New[int, int](3)
+	tr.Put(1, 1)
+	tr.Put(2, 2)
+	tr.Put(3, 3)
+
+	sum := func(values []int) int {
+		total := 0
+		for _, v := range values {
+			total += v
+		}
+		return total
+	}
+
+	assert.Equal(t, []int{1, 3, 5}, RollingAggregate(tr, 2, sum), "rolling sum over a window of 2")
+	assert.Equal(t, []int{1, 3, 6}, RollingAggregate(tr, 5, sum), "a wide window uses what is available")
+}
+
+func TestCountValueInversions(t *testing.T) {
+	tr := New[int, int](3)
+	for i, v := range []int{3, 1, 2} {
+		tr.Put(i, v)
+	}
+	assert.Equal(t, 2, CountValueInversions(tr), "(3,1) and (3,2) are inversions")
+
+	tr = New[int, int](3)
+	for i, v := range []int{5, 4, 3, 2, 1} {
+		tr.Put(i, v)
+	}
+	assert.Equal(t, 10, CountValueInversions(tr), "a reversed sequence inverts every pair")
+	assert.Equal(t, 0, CountValueInversions(New[int, int](3)), "empty tree has no inversions")
+}
+
+func TestMinMaxByValue(t *testing.T) {
+	tr := New[int, int](3)
+	for k, v := range map[int]int{1: 40, 2: 10, 3: 70, 4: 10, 5: 70} {
+		tr.Put(k, v)
+	}
+
+	key, value, found := MinByValue(tr)
+	assert.True(t, found, "min should be found")
+	assert.Equal(t, 2, key, "smallest value 10 first appears at key 2")
+	assert.Equal(t, 10, value, "min value should be 10")
+
+	key, value, _ = MaxByValue(tr)
+	assert.Equal(t, 3, key, "largest value 70 first appears at key 3")
+	assert.Equal(t, 70, value, "max value should be 70")
+
+	_, _, found = MaxByValue(New[int, int](3))
+	assert.False(t, found, "empty tree has no max")
+}
+
+func TestNearestByValue(t *testing.T) {
+	tr := New[string, int](3)
+	tr.Put("a", 10)
+	tr.Put("b", 42)
+	tr.Put("c", 17)
+	tr.Put("d", 23)
+
+	key, value, found := NearestByValue(tr, 20)
+	assert.True(t, found, "a nearest value should be found")
+	assert.Equal(t, "c", key, "17 and 23 are equally close, the smaller key wins")
+	assert.Equal(t, 17, value, "nearest value should be 17")
+
+	key, _, _ = NearestByValue(tr, 40)
+	assert.Equal(t, "b", key, "42 is nearest to 40")
+
+	u := New[int, uint](3)
+	u.Put(1, 5)
+	u.Put(2, 9)
+	key2, _, _ := NearestByValue(u, 8)
+	assert.Equal(t, 2, key2, "unsigned distances should not wrap around")
+
+	small := New[string, int8](3)
+	small.Put("a", -100)
+	small.Put("b", 50)
+	key, _, _ = NearestByValue(small, 100)
+	assert.Equal(t, "b", key, "a distance of 200 should not wrap in int8")
+	key, _, _ = NearestByValue(small, math.MinInt8)
+	assert.Equal(t, "a", key, "-100 is nearest to MinInt8")
+
+	wide := New[string, int](3)
+	wide.Put("a", math.MinInt)
+	wide.Put("b", 0)
+	key, _, _ = NearestByValue(wide, math.MaxInt)
+	assert.Equal(t, "b", key, "distances near MinInt should not wrap")
+
+	floats := New[string, float64](3)
+	floats.Put("a", -math.MaxFloat64)
+	floats.Put("b", 0.25)
+	floats.Put("c", 0.5)
+	key, _, _ = NearestByValue(floats, 0.3)
+	assert.Equal(t, "b", key, "fractional distances should be compared exactly")
+	key, _, _ = NearestByValue(floats, math.MaxFloat64)
+	assert.NotEqual(t, "a", key, "a distance that overflows to infinity should lose")
+
+	_, _, found = NearestByValue(New[string, int](3), 1)
+	assert.False(t, found, "empty tree has no nearest value")
+}
+
+func TestGroupBy(t *testing.T) {
+	tr := exampleTree()
+
+	groups := GroupBy(tr, func(_ int, value string) bool {
+		return strings.ContainsAny(value, "aeiou")
+	})
+	assert.Equal(t, []int{1, 5, 9}, groups[true], "a, e and i are vowels")
+	assert.Equal(t, []int{2, 3, 4, 6, 7, 8}, groups[false], "the rest are consonants")
+}
+
+func TestInvertByValue(t *testing.T) {
+	tr := New[int, string](3)
+	tr.Put(1, "red")
+	tr.Put(2, "blue")
+	tr.Put(3, "red")
+	tr.Put(4, "green")
+
+	inverted := InvertByValue(tr)
+	assert.Equal(t, 3, inverted.Size(), "there are three distinct values")
+
+	keys, found := inverted.Get("red")
+	assert.True(t, found, "red should be a key of the inverted tree")
+	assert.Equal(t, []int{1, 3}, keys, "keys sharing a value should be grouped in order")
+
+	keys, _ = inverted.Get("blue")
+	assert.Equal(t, []int{2}, keys, "blue maps to a single key")
+}
+
+func TestMap(t *testing.T) {
+	tr := exampleTree()
+
+	var seen []int
+	lengths := Map(tr, func(key int, value string) int {
+		seen = append(seen, key)
+		return key * len(value)
+	})
+
+	assert.Equal(t, tr.Keys(), lengths.Keys(), "keys should be unchanged")
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, lengths.Values(), "values should be transformed")
+	assert.Equal(t, tr.Keys(), seen, "fn should be called in key order")
+	assert.Equal(t, tr.String(), lengths.String(), "the shape should be copied")
+	assert.Equal(t, tr.m, lengths.m, "order should be carried over")
+	assert.NoError(t, lengths.Validate())
+
+	lengths.Put(10, 10)
+	assert.Equal(t, 9, tr.Size(), "the original should be independent of the result")
+
+	empty := Map(New[int, string](3), func(int, string) int { return 0 })
+	assert.True(t, empty.Empty())
+	empty.Put(1, 1)
+	assert.Equal(t, 1, empty.Size(), "a mapped empty tree should accept writes")
+}
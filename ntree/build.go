@@ -0,0 +1,229 @@
+package ntree
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+)
+
+// Index builds a tree of order m holding every item keyed by keyFn(item).
+// When several items share a key the last one in items wins.
+func Index[T any, K cmp.Ordered](m int, items []T, keyFn func(T) K) *Tree[K, T] {
+	t := New[K, T](m)
+	for _, item := range items {
+		t.Put(keyFn(item), item)
+	}
+
+	return t
+}
+
+// BuildFromSorted builds a tree of order m from keys and their values in
+// O(n), bottom-up, without any splits. keys must be strictly increasing
+// under comparator; an error is returned if they are not, if the slices
+// differ in length, or if m is below 3.
+func BuildFromSorted[K comparable, V any](m int, keys []K, values []V, comparator func(a, b K) int) (*Tree[K, V], error) {
+	if m < 3 {
+		return nil, fmt.Errorf("ntree: order m must be >= 3, got %d", m)
+	}
+
+	elements, err := sortedElements(keys, values, comparator)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tree[K, V]{Comparator: comparator, m: m}
+	t.load(elements)
+	return t, nil
+}
+
+// sortedElements pairs keys with values after checking that the keys are
+// strictly increasing under compare
+func sortedElements[K comparable, V any](keys []K, values []V, compare func(a, b K) int) ([]*Element[K, V], error) {
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("ntree: %d keys but %d values", len(keys), len(values))
+	}
+
+	sorted := make([]Element[K, V], len(keys))
+	elements := make([]*Element[K, V], len(keys))
+	for i := range keys {
+		sorted[i] = Element[K, V]{Key: keys[i], Value: values[i]}
+		elements[i] = &sorted[i]
+	}
+
+	if err := validateSorted(sorted, compare); err != nil {
+		return nil, err
+	}
+
+	return elements, nil
+}
+
+// ValidateSortedInput checks that elements are in strictly ascending key
+// order under compare, which also rules out duplicates. It returns an
+// error naming the first offending index, or nil if the input can be bulk
+// loaded as is.
+func ValidateSortedInput[K cmp.Ordered, V any](elements []Element[K, V], compare func(a, b K) int) error {
+	return validateSorted(elements, compare)
+}
+
+func validateSorted[K comparable, V any](elements []Element[K, V], compare func(a, b K) int) error {
+	for i := 1; i < len(elements); i++ {
+		comp := compare(elements[i-1].Key, elements[i].Key)
+		switch {
+		case comp == 0:
+			return fmt.Errorf("ntree: duplicate key %v at index %d", elements[i].Key, i)
+		case comp > 0:
+			return fmt.Errorf("ntree: key %v at index %d is not greater than key %v at index %d",
+				elements[i].Key, i, elements[i-1].Key, i-1)
+		}
+	}
+
+	return nil
+}
+
+// CollectChan builds a tree of order m from the elements received on ch,
+// returning once ch is closed. Later elements overwrite earlier ones with
+// the same key.
+func CollectChan[K cmp.Ordered, V any](m int, ch <-chan Element[K, V]) *Tree[K, V] {
+	t := New[K, V](m)
+	for e := range ch {
+		t.Put(e.Key, e.Value)
+	}
+
+	return t
+}
+
+// FromMap builds a tree of order m holding every entry of src. Map order is
+// random, so the keys are sorted first and the tree is bulk loaded.
+func FromMap[K cmp.Ordered, V any](m int, src map[K]V) *Tree[K, V] {
+	t := New[K, V](m)
+	keys := slices.Sorted(maps.Keys(src))
+
+	elements := make([]*Element[K, V], len(keys))
+	for i, key := range keys {
+		elements[i] = &Element[K, V]{Key: key, Value: src[key]}
+	}
+
+	t.load(elements)
+	return t
+}
+
+// ToMap returns a map holding every entry of the tree
+func (t *Tree[K, V]) ToMap() map[K]V {
+	dst := make(map[K]V, t.size)
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		dst[e.Key] = e.Value
+		return true
+	})
+
+	return dst
+}
+
+// RandomTree builds a tree of order m with n distinct random integer keys
+// drawn from rng, each holding a value from genVal. The tree is built by
+// ordinary insertion, so it is a valid B-tree; it is meant for property
+// and fuzz tests.
+func RandomTree[V any](m, n int, rng *rand.Rand, genVal func() V) *Tree[int, V] {
+	t := New[int, V](m)
+	for t.Size() < n {
+		key := rng.Int()
+		if !t.Contains(key) {
+			t.Put(key, genVal())
+		}
+	}
+
+	return t
+}
+
+// ReplaceAll replaces the contents of the tree with sorted, bulk loading it
+// in O(n) instead of inserting element by element. It panics if sorted is
+// not in strictly ascending key order, leaving the tree unchanged.
+func (t *Tree[K, V]) ReplaceAll(sorted []Element[K, V]) {
+	if err := validateSorted(sorted, t.Comparator); err != nil {
+		panic(err)
+	}
+
+	elements := make([]*Element[K, V], len(sorted))
+	for i := range sorted {
+		elements[i] = &Element[K, V]{Key: sorted[i].Key, Value: sorted[i].Value}
+	}
+
+	t.load(elements)
+	t.deletes = 0
+}
+
+// load replaces the contents of the tree with elements, which must be in
+// strictly ascending key order, building the nodes bottom-up in O(n). The
+// tree gets the smallest height that can hold the elements, and each node
+// splits its keys as evenly as possible between the fewest children it
+// may have.
+func (t *Tree[K, V]) load(elements []*Element[K, V]) {
+	t.mutate()
+	t.Root, t.size = nil, len(elements)
+	if len(elements) == 0 {
+		return
+	}
+
+	// capacity[h] is the most keys a subtree of height h can hold, m^h-1
+	capacity := []int{0, t.maxElements()}
+	for capacity[len(capacity)-1] < len(elements) {
+		capacity = append(capacity, capacity[len(capacity)-1]*t.m+t.maxElements())
+	}
+
+	t.Root = t.loadNode(elements, capacity, len(capacity)-1, nil)
+}
+
+func (t *Tree[K, V]) loadNode(elements []*Element[K, V], capacity []int, height int, parent *Node[K, V]) *Node[K, V] {
+	n := &Node[K, V]{Parent: parent}
+	if height == 1 {
+		n.Elements = append([]*Element[K, V](nil), elements...)
+		n.subtreeSize = len(elements)
+		return n
+	}
+
+	minChildren := t.minElements() + 1
+	if parent == nil {
+		minChildren = 2
+	}
+
+	// m children of full subtrees and their m-1 separators fill capacity
+	// exactly, so this never exceeds m
+	children := (len(elements) + 1 + capacity[height-1]) / (capacity[height-1] + 1)
+	children = max(children, minChildren)
+
+	keys := len(elements) - (children - 1)
+	per, extra := keys/children, keys%children
+
+	n.Elements = make([]*Element[K, V], 0, children-1)
+	n.Children = make([]*Node[K, V], 0, children)
+	for i := 0; i < children; i++ {
+		size := per
+		if i < extra {
+			size++
+		}
+
+		n.Children = append(n.Children, t.loadNode(elements[:size], capacity, height-1, n))
+		elements = elements[size:]
+
+		if i < children-1 {
+			n.Elements = append(n.Elements, elements[0])
+			elements = elements[1:]
+		}
+	}
+
+	t.recount(n)
+	return n
+}
+
+// CompareOrders bulk loads the same sorted elements into trees of orders a
+// and b and reports the height and node count of each, to help choose an
+// order for a dataset. It panics if elements are not strictly ascending or
+// an order is below 3.
+func CompareOrders[K cmp.Ordered, V any](elements []Element[K, V], a, b int) (heightA, heightB, nodesA, nodesB int) {
+	ta, tb := New[K, V](a), New[K, V](b)
+	ta.ReplaceAll(elements)
+	tb.ReplaceAll(elements)
+
+	return ta.Height(), tb.Height(), ta.NodeCount(), tb.NodeCount()
+}
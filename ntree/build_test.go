@@ -0,0 +1,157 @@
+package ntree
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	ID   int
+	Name string
+}
+
+func TestIndex(t *testing.T) {
+	users := []user{{3, "carol"}, {1, "alice"}, {2, "bob"}, {1, "alicia"}}
+	tr := Index(3, users, func(u user) int { return u.ID })
+
+	assert.Equal(t, 3, tr.Size(), "duplicate IDs should collapse")
+
+	u, found := tr.Get(2)
+	assert.True(t, found, "ID 2 should be indexed")
+	assert.Equal(t, "bob", u.Name, "ID 2 should be bob")
+
+	u, _ = tr.Get(1)
+	assert.Equal(t, "alicia", u.Name, "the last item with a duplicate ID should win")
+}
+
+func TestBuildFromSorted(t *testing.T) {
+	keys := make([]int, 1000)
+	values := make([]int, 1000)
+	for i := range keys {
+		keys[i], values[i] = i*2, i
+	}
+
+	tr, err := BuildFromSorted(5, keys, values, cmp.Compare[int])
+	assert.NoError(t, err)
+	assert.NoError(t, tr.Validate(), "bulk loaded tree should be valid")
+	assert.Equal(t, keys, tr.Keys(), "keys should be loaded in order")
+	assert.Equal(t, values, tr.Values(), "values should follow their keys")
+
+	tr.Put(3, -1)
+	assert.Equal(t, 1001, tr.Size(), "the loaded tree should accept further writes")
+	assert.NoError(t, tr.Validate())
+
+	_, err = BuildFromSorted(5, []int{1, 2}, []int{1}, cmp.Compare[int])
+	assert.Error(t, err, "mismatched lengths should fail")
+
+	_, err = BuildFromSorted(5, []int{1, 3, 3}, []int{1, 2, 3}, cmp.Compare[int])
+	assert.Error(t, err, "repeated keys should fail")
+
+	_, err = BuildFromSorted(2, keys, values, cmp.Compare[int])
+	assert.Error(t, err, "orders below 3 should fail")
+
+	empty, err := BuildFromSorted[int, int](3, nil, nil, cmp.Compare[int])
+	assert.NoError(t, err)
+	assert.True(t, empty.Empty(), "empty input should build an empty tree")
+}
+
+func TestValidateSortedInput(t *testing.T) {
+	sorted := []Element[int, string]{{1, "a"}, {2, "b"}, {5, "c"}}
+	assert.NoError(t, ValidateSortedInput(sorted, cmp.Compare[int]), "sorted input should validate")
+
+	dup := []Element[int, string]{{1, "a"}, {2, "b"}, {2, "c"}, {3, "d"}}
+	err := ValidateSortedInput(dup, cmp.Compare[int])
+	assert.Error(t, err, "duplicate keys should fail")
+	assert.Contains(t, err.Error(), "index 2", "error should name the offending index")
+
+	unsorted := []Element[int, string]{{3, "a"}, {1, "b"}}
+	assert.Error(t, ValidateSortedInput(unsorted, cmp.Compare[int]), "descending keys should fail")
+}
+
+func TestCollectChan(t *testing.T) {
+	ch := make(chan Element[int, string], 10)
+	for i := 1; i <= 10; i++ {
+		ch <- Element[int, string]{Key: i, Value: string(rune('a' + i - 1))}
+	}
+	close(ch)
+
+	tr := CollectChan(4, ch)
+	assert.Equal(t, 10, tr.Size(), "all elements should be collected")
+	for i := 1; i <= 10; i++ {
+		value, found := tr.Get(i)
+		assert.True(t, found, "collected key should be present")
+		assert.Equal(t, string(rune('a'+i-1)), value, "value should match")
+	}
+}
+
+func TestReplaceAll(t *testing.T) {
+	tr := exampleTree()
+
+	tr.ReplaceAll([]Element[int, string]{{20, "t"}, {30, "u"}, {40, "v"}, {50, "w"}, {60, "x"}, {70, "y"}})
+	assert.Equal(t, 6, tr.Size(), "size should match the new contents")
+	assert.Equal(t, []int{20, 30, 40, 50, 60, 70}, tr.Keys(), "only the new keys should remain")
+	for key := 1; key <= 9; key++ {
+		_, found := tr.Get(key)
+		assert.False(t, found, "old key should be gone")
+	}
+
+	value, found := tr.Get(40)
+	assert.True(t, found, "new key should be found")
+	assert.Equal(t, "v", value, "new value should be stored")
+
+	assert.Panics(t, func() {
+		tr.ReplaceAll([]Element[int, string]{{2, "a"}, {1, "b"}})
+	}, "unsorted input should panic")
+	assert.Equal(t, 6, tr.Size(), "a rejected replacement should leave the tree unchanged")
+
+	tr.ReplaceAll(nil)
+	assert.True(t, tr.Empty(), "replacing with nothing empties the tree")
+}
+
+func TestFromMapToMap(t *testing.T) {
+	src := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		src[fmt.Sprintf("k%03d", i)] = i
+	}
+
+	tr := FromMap(5, src)
+	assert.NoError(t, tr.Validate(), "the built tree should be valid")
+	assert.Equal(t, len(src), tr.Size())
+	assert.True(t, slices.IsSorted(tr.Keys()), "keys should be in order")
+	assert.Equal(t, src, tr.ToMap(), "the map should round-trip")
+
+	empty := FromMap[int, int](3, nil)
+	assert.True(t, empty.Empty(), "a nil map should give an empty tree")
+	assert.Equal(t, map[int]int{}, empty.ToMap(), "an empty tree should give an empty map")
+}
+
+func TestRandomTree(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, m := range []int{3, 4, 7, 16} {
+		for _, n := range []int{0, 1, 10, 500} {
+			calls := 0
+			tr := RandomTree(m, n, rng, func() int { calls++; return calls })
+			assert.NoError(t, tr.Validate(), "m=%d n=%d: random tree should be valid", m, n)
+			assert.Equal(t, n, tr.Size(), "m=%d n=%d: random tree should hold n keys", m, n)
+			assert.Equal(t, n, calls, "m=%d n=%d: one value per key", m, n)
+		}
+	}
+}
+
+func TestCompareOrders(t *testing.T) {
+	elements := make([]Element[int, int], 1000)
+	for i := range elements {
+		elements[i] = Element[int, int]{Key: i, Value: i}
+	}
+
+	heightA, heightB, nodesA, nodesB := CompareOrders(elements, 3, 16)
+	assert.Equal(t, 7, heightA, "1000 keys need 7 levels at order 3")
+	assert.Equal(t, 3, heightB, "1000 keys need 3 levels at order 16")
+	assert.LessOrEqual(t, heightB, heightA, "the higher order should not need more levels")
+	assert.Less(t, nodesB, nodesA, "the higher order should need fewer nodes")
+}
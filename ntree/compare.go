@@ -0,0 +1,194 @@
+package ntree
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// SymmetricDifference returns, in ascending order, the keys present in
+// exactly one of t and other. Both trees are merge-walked in order using
+// t's comparator.
+func (t *Tree[K, V]) SymmetricDifference(other *Tree[K, V]) []K {
+	a, b := t.elements(), other.elements()
+
+	var keys []K
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		comp := t.Comparator(a[i].Key, b[j].Key)
+		switch {
+		case comp == 0:
+			i++
+			j++
+		case comp < 0:
+			keys = append(keys, a[i].Key)
+			i++
+		case comp > 0:
+			keys = append(keys, b[j].Key)
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		keys = append(keys, a[i].Key)
+	}
+	for ; j < len(b); j++ {
+		keys = append(keys, b[j].Key)
+	}
+
+	return keys
+}
+
+// CompareReport describes how other differs from t: the size difference,
+// keys of t missing from other, extra keys only other holds, and keys
+// whose values differ under valueEq. It returns an empty string when the
+// trees hold the same contents.
+func (t *Tree[K, V]) CompareReport(other *Tree[K, V], valueEq func(a, b V) bool) string {
+	a, b := t.elements(), other.elements()
+
+	var missing, extra, mismatched []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		var comp int
+		switch {
+		case i == len(a):
+			comp = 1
+		case j == len(b):
+			comp = -1
+		default:
+			comp = t.Comparator(a[i].Key, b[j].Key)
+		}
+
+		switch {
+		case comp == 0:
+			if !valueEq(a[i].Value, b[j].Value) {
+				mismatched = append(mismatched, fmt.Sprintf("%v: %v != %v", a[i].Key, a[i].Value, b[j].Value))
+			}
+			i++
+			j++
+		case comp < 0:
+			missing = append(missing, fmt.Sprint(a[i].Key))
+			i++
+		case comp > 0:
+			extra = append(extra, fmt.Sprint(b[j].Key))
+			j++
+		}
+	}
+
+	var sb strings.Builder
+	if t.Size() != other.Size() {
+		fmt.Fprintf(&sb, "size: %d != %d\n", t.Size(), other.Size())
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(&sb, "missing keys: %s\n", strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		fmt.Fprintf(&sb, "extra keys: %s\n", strings.Join(extra, ", "))
+	}
+	if len(mismatched) > 0 {
+		fmt.Fprintf(&sb, "value mismatches: %s\n", strings.Join(mismatched, ", "))
+	}
+
+	return sb.String()
+}
+
+// IsStructurallyEqual reports whether t and other have the same shape:
+// the same nodes at the same positions holding the same keys. Values are
+// not compared.
+func (t *Tree[K, V]) IsStructurallyEqual(other *Tree[K, V]) bool {
+	return t.size == other.size && t.m == other.m && t.sameShape(t.Root, other.Root)
+}
+
+func (t *Tree[K, V]) sameShape(a, b *Node[K, V]) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if len(a.Elements) != len(b.Elements) || len(a.Children) != len(b.Children) {
+		return false
+	}
+
+	for i := range a.Elements {
+		if t.Comparator(a.Elements[i].Key, b.Elements[i].Key) != 0 {
+			return false
+		}
+	}
+
+	for i := range a.Children {
+		if !t.sameShape(a.Children[i], b.Children[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Equal reports whether t and other hold the same keys, compared with t's
+// comparator, with values equal under valueEq. The order m and the node
+// layout of the two trees may differ. Trees of different sizes are
+// rejected without walking them.
+func (t *Tree[K, V]) Equal(other *Tree[K, V], valueEq func(a, b V) bool) bool {
+	if t.Size() != other.Size() {
+		return false
+	}
+
+	a, b := newCursor(t), newCursor(other)
+	for x, y := a.next(), b.next(); x != nil && y != nil; x, y = a.next(), b.next() {
+		if t.Comparator(x.Key, y.Key) != 0 || !valueEq(x.Value, y.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FirstDivergence merge-walks t and other in ascending order and returns
+// the smallest key that is present in only one of them or whose values
+// differ under valueEq. ok is false when the trees hold the same contents.
+func (t *Tree[K, V]) FirstDivergence(other *Tree[K, V], valueEq func(a, b V) bool) (key K, ok bool) {
+	a, b := t.elements(), other.elements()
+
+	i, j := 0, 0
+	for ; i < len(a) && j < len(b); i, j = i+1, j+1 {
+		comp := t.Comparator(a[i].Key, b[j].Key)
+		switch {
+		case comp < 0:
+			return a[i].Key, true
+		case comp > 0:
+			return b[j].Key, true
+		case !valueEq(a[i].Value, b[j].Value):
+			return a[i].Key, true
+		}
+	}
+
+	switch {
+	case i < len(a):
+		return a[i].Key, true
+	case j < len(b):
+		return b[j].Key, true
+	}
+
+	return key, false
+}
+
+// InnerJoin merge-walks left and right in ascending key order and calls
+// emit for every key present in both, in O(n+m). Keys are compared with
+// left's comparator.
+func InnerJoin[K cmp.Ordered, L, R any](left *Tree[K, L], right *Tree[K, R], emit func(key K, l L, r R)) {
+	a, b := left.elements(), right.elements()
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		comp := left.Comparator(a[i].Key, b[j].Key)
+		switch {
+		case comp == 0:
+			emit(a[i].Key, a[i].Value, b[j].Value)
+			i++
+			j++
+		case comp < 0:
+			i++
+		case comp > 0:
+			j++
+		}
+	}
+}
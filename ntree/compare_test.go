@@ -0,0 +1,99 @@
+package ntree
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymmetricDifference(t *testing.T) {
+	a := New[int, string](3)
+	for _, k := range []int{1, 2, 3, 4, 5, 6} {
+		a.Put(k, "a")
+	}
+
+	b := New[int, string](4)
+	for _, k := range []int{4, 5, 6, 7, 8} {
+		b.Put(k, "b")
+	}
+
+	assert.Equal(t, []int{1, 2, 3, 7, 8}, a.SymmetricDifference(b), "keys in exactly one tree")
+	assert.Equal(t, []int{1, 2, 3, 7, 8}, b.SymmetricDifference(a), "difference should be symmetric")
+	assert.Empty(t, a.SymmetricDifference(a), "a tree has no difference with itself")
+}
+
+func TestCompareReport(t *testing.T) {
+	eq := func(a, b string) bool { return a == b }
+	tr := exampleTree()
+	assert.Empty(t, tr.CompareReport(exampleTree(), eq), "identical trees should produce no report")
+
+	other := exampleTree()
+	other.Put(4, "changed")
+	other.Put(10, "j")
+	other.ExtractMatching(func(key int, _ string) bool { return key == 2 })
+
+	report := tr.CompareReport(other, eq)
+	assert.Contains(t, report, "missing keys: 2", "report should name the missing key")
+	assert.Contains(t, report, "extra keys: 10", "report should name the extra key")
+	assert.Contains(t, report, "4: d != changed", "report should name the changed key")
+}
+
+func TestEqual(t *testing.T) {
+	eq := func(a, b int) bool { return a == b }
+	a, b := New[int, int](3), New[int, int](8)
+	for key := 0; key < 200; key++ {
+		a.Put(key, key)
+		b.Put(199-key, 199-key)
+	}
+
+	assert.True(t, a.Equal(b, eq), "same contents with different orders should be equal")
+	assert.True(t, b.Equal(a, eq), "equality should be symmetric")
+	assert.True(t, New[int, int](3).Equal(New[int, int](4), eq), "empty trees should be equal")
+
+	b.Put(50, -1)
+	assert.False(t, a.Equal(b, eq), "a differing value should be detected")
+	b.Put(50, 50)
+
+	b.Remove(70)
+	b.Put(1000, 70)
+	assert.False(t, a.Equal(b, eq), "a differing key should be detected")
+
+	calls := 0
+	b.Remove(1000)
+	a.Equal(b, func(x, y int) bool { calls++; return x == y })
+	assert.Equal(t, 0, calls, "different sizes should short-circuit")
+}
+
+func TestFirstDivergence(t *testing.T) {
+	eq := func(a, b string) bool { return a == b }
+	tr := exampleTree()
+
+	_, ok := tr.FirstDivergence(exampleTree(), eq)
+	assert.False(t, ok, "identical trees should not diverge")
+
+	other := exampleTree()
+	other.Put(7, "changed")
+	key, ok := tr.FirstDivergence(other, eq)
+	assert.True(t, ok, "trees should diverge")
+	assert.Equal(t, 7, key, "trees should diverge at the changed key")
+
+	other = exampleTree()
+	other.Put(10, "j")
+	key, _ = tr.FirstDivergence(other, eq)
+	assert.Equal(t, 10, key, "a trailing extra key is a divergence")
+}
+
+func TestInnerJoin(t *testing.T) {
+	names := exampleTree()
+	scores := New[int, int](3)
+	for _, k := range []int{0, 2, 4, 6, 8, 10} {
+		scores.Put(k, k*10)
+	}
+
+	var joined []string
+	InnerJoin(names, scores, func(key int, name string, score int) {
+		joined = append(joined, fmt.Sprintf("%d:%s:%d", key, name, score))
+	})
+	assert.Equal(t, []string{"2:b:20", "4:d:40", "6:f:60", "8:h:80"}, joined, "only shared keys should be emitted")
+}
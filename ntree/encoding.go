@@ -0,0 +1,197 @@
+package ntree
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// jsonElement is the JSON form of a key-value pair
+type jsonElement[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// MarshalJSON encodes the contents of the tree as a JSON array of
+// {"key": ..., "value": ...} objects in ascending key order. The shape of
+// the tree is not encoded.
+func (t *Tree[K, V]) MarshalJSON() ([]byte, error) {
+	elements := make([]jsonElement[K, V], 0, t.size)
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		elements = append(elements, jsonElement[K, V]{Key: e.Key, Value: e.Value})
+		return true
+	})
+
+	return json.Marshal(elements)
+}
+
+// UnmarshalJSON replaces the contents of the tree with the pairs encoded by
+// MarshalJSON, inserting them with Put. JSON does not carry the order m or
+// the comparator, so the tree must already have been constructed with New;
+// decoding into a zero Tree returns an error. On error the tree is left
+// unchanged.
+func (t *Tree[K, V]) UnmarshalJSON(data []byte) error {
+	if t.m < 3 || t.Comparator == nil {
+		return errors.New("ntree: UnmarshalJSON needs a tree constructed with New")
+	}
+
+	var elements []jsonElement[K, V]
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return err
+	}
+
+	t.Clear()
+	for _, e := range elements {
+		t.Put(e.Key, e.Value)
+	}
+
+	return nil
+}
+
+// gobTree is the gob form of a tree: its order and its pairs in key order
+type gobTree[K comparable, V any] struct {
+	M      int
+	Keys   []K
+	Values []V
+}
+
+// GobEncode encodes the order m and the key-value pairs of the tree in
+// ascending key order, so it can be stored with encoding/gob.
+func (t *Tree[K, V]) GobEncode() ([]byte, error) {
+	g := gobTree[K, V]{M: t.m, Keys: t.Keys(), Values: t.Values()}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(g); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GobDecode replaces the contents of the tree with those encoded by
+// GobEncode, restoring the encoded order m and bulk loading the pairs. Gob
+// does not carry the comparator, so the tree must already have been
+// constructed with New. On error the tree is left unchanged.
+func (t *Tree[K, V]) GobDecode(data []byte) error {
+	if t.Comparator == nil {
+		return errors.New("ntree: GobDecode needs a tree constructed with New")
+	}
+
+	var g gobTree[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&g); err != nil {
+		return err
+	}
+
+	if g.M < 3 {
+		return fmt.Errorf("ntree: order m must be >= 3, got %d", g.M)
+	}
+
+	elements, err := sortedElements(g.Keys, g.Values, t.Comparator)
+	if err != nil {
+		return err
+	}
+
+	t.mutate()
+	t.m = g.M
+	t.load(elements)
+	t.deletes = 0
+
+	return nil
+}
+
+// PageToken returns up to limit elements in ascending key order following
+// the key encoded in afterToken, and a token to fetch the next page. An
+// empty afterToken starts from the smallest key, and an empty nextToken
+// means there is nothing left. The token is the base64 encoded JSON form of
+// the last key returned, so keys must be JSON serializable. It panics if
+// limit is below 1 or afterToken cannot be decoded; check tokens from
+// untrusted callers with ParsePageToken first.
+func (t *Tree[K, V]) PageToken(afterToken string, limit int) (elements []Element[K, V], nextToken string) {
+	if limit < 1 {
+		panic(fmt.Errorf("ntree: PageToken limit must be >= 1, got %d", limit))
+	}
+
+	c := newCursor(t)
+	if afterToken != "" {
+		after, err := t.ParsePageToken(afterToken)
+		if err != nil {
+			panic(err)
+		}
+
+		c.seek(after)
+		if e := c.next(); e != nil && t.Comparator(e.Key, after) != 0 {
+			c.seek(after)
+		}
+	}
+
+	for e := c.next(); e != nil; e = c.next() {
+		elements = append(elements, *e)
+		if len(elements) == limit {
+			break
+		}
+	}
+
+	if len(elements) < limit || c.next() == nil {
+		return elements, ""
+	}
+
+	nextToken, err := encodePageToken(elements[len(elements)-1].Key)
+	if err != nil {
+		panic(fmt.Errorf("ntree: PageToken cannot encode key: %w", err))
+	}
+
+	return elements, nextToken
+}
+
+// ParsePageToken decodes a token returned by PageToken, reporting an error
+// if it is not one. It returns the last key of the page the token follows.
+func (t *Tree[K, V]) ParsePageToken(token string) (K, error) {
+	key, err := decodePageToken[K](token)
+	if err != nil {
+		return key, fmt.Errorf("ntree: invalid page token: %w", err)
+	}
+
+	return key, nil
+}
+
+// WriteCSV writes one key,value record per element in ascending key order,
+// formatting keys and values with keyStr and valStr, and flushes w. It
+// returns the first error from the writer. To add a header row, write it
+// to w before calling WriteCSV.
+func (t *Tree[K, V]) WriteCSV(w *csv.Writer, keyStr func(K) string, valStr func(V) string) error {
+	var err error
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		err = w.Write([]string{keyStr(e.Key), valStr(e.Value)})
+		return err == nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func encodePageToken[K comparable](key K) (string, error) {
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodePageToken[K comparable](token string) (key K, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return key, err
+	}
+
+	err = json.Unmarshal(b, &key)
+	return key, err
+}
@@ -0,0 +1,135 @@
+package ntree
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageToken(t *testing.T) {
+	tr := exampleTree()
+
+	var pages [][]int
+	token := ""
+	for {
+		page, next := tr.PageToken(token, 4)
+		pages = append(pages, elementKeys(page))
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	assert.Equal(t, [][]int{{1, 2, 3, 4}, {5, 6, 7, 8}, {9}}, pages, "pages should follow each other")
+
+	page, next := tr.PageToken("", 9)
+	assert.Len(t, page, 9, "a page can hold the whole tree")
+	assert.Empty(t, next, "an exact final page has no next token")
+
+	// resuming after a key removed since the token was issued
+	_, token = tr.PageToken("", 4)
+	tr.Remove(4)
+	page, _ = tr.PageToken(token, 2)
+	assert.Equal(t, []int{5, 6}, elementKeys(page), "paging should resume after the token key")
+
+	key, err := tr.ParsePageToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, key, "a token should decode to the last key of its page")
+
+	_, err = tr.ParsePageToken("not a token!")
+	assert.Error(t, err, "a corrupted token should be reported")
+	assert.Panics(t, func() { tr.PageToken("not a token!", 4) }, "a corrupted token is not the end of the data")
+	assert.Panics(t, func() { tr.PageToken("", 0) }, "a limit below 1 should panic")
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteCSV(t *testing.T) {
+	tr := exampleTree()
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	assert.NoError(t, w.Write([]string{"key", "value"}))
+	assert.NoError(t, tr.WriteCSV(w, strconv.Itoa, func(v string) string { return v }))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, tr.Size()+1, "one header and one record per element")
+	assert.Equal(t, []string{"key", "value"}, records[0], "the header comes first")
+	for i, record := range records[1:] {
+		assert.Equal(t, []string{strconv.Itoa(i + 1), string(rune('a' + i))}, record, "records should be in key order")
+	}
+
+	err = tr.WriteCSV(csv.NewWriter(failingWriter{}), strconv.Itoa, func(v string) string { return v })
+	assert.EqualError(t, err, "disk full", "writer errors should be returned")
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tr := exampleTree()
+
+	data, err := json.Marshal(tr)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), `[{"key":1,"value":"a"},{"key":2,"value":"b"}`),
+		"pairs should be encoded in key order")
+
+	decoded := New[int, string](3)
+	decoded.Put(100, "stale")
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, tr.Keys(), decoded.Keys(), "keys should round-trip")
+	assert.Equal(t, tr.Values(), decoded.Values(), "values should round-trip")
+	assert.Equal(t, 3, decoded.m, "decoding should keep the existing order")
+	assert.NoError(t, decoded.Validate(), "decoded tree should be valid")
+
+	var zero Tree[int, string]
+	assert.Error(t, json.Unmarshal(data, &zero), "a zero tree cannot be decoded into")
+
+	assert.Error(t, json.Unmarshal([]byte(`{"key":1}`), decoded), "malformed input should fail")
+	assert.Equal(t, tr.Keys(), decoded.Keys(), "a failed decode should leave the tree unchanged")
+
+	data, err = json.Marshal(New[int, string](3))
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(data), "an empty tree encodes as an empty array")
+}
+
+func TestGobRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tr := New[int, string](7)
+	for tr.Size() < 10000 {
+		k := rng.Intn(1 << 30)
+		tr.Put(k, strconv.Itoa(k))
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(tr))
+
+	decoded := New[int, string](3)
+	decoded.Put(-1, "stale")
+	assert.NoError(t, gob.NewDecoder(&buf).Decode(decoded))
+	assert.Equal(t, 10000, decoded.Size(), "size should round-trip")
+	assert.Equal(t, 7, decoded.m, "the encoded order should be restored")
+	assert.NoError(t, decoded.Validate(), "decoded tree should be valid")
+	assert.False(t, decoded.Contains(-1), "decoding should replace the old contents")
+
+	keys := tr.Keys()
+	for _, i := range []int{0, 1234, 5000, 9999} {
+		v, ok := decoded.Get(keys[i])
+		assert.True(t, ok, "key %d should survive", keys[i])
+		assert.Equal(t, strconv.Itoa(keys[i]), v)
+	}
+
+	var zero Tree[int, string]
+	data, err := tr.GobEncode()
+	assert.NoError(t, err)
+	assert.Error(t, zero.GobDecode(data), "a zero tree cannot be decoded into")
+}
@@ -0,0 +1,90 @@
+package ntree
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GraphNode is a tree node exported for graph tools, identified by the
+// position at which a breadth-first walk reaches it.
+type GraphNode[K comparable] struct {
+	ID   int
+	Keys []K
+}
+
+// GraphEdge connects the node with ID From to its child with ID To.
+type GraphEdge struct {
+	From int
+	To   int
+}
+
+// GraphExport returns the nodes of the tree and the parent-child edges
+// between them. Node IDs are assigned in breadth-first order starting at 0
+// for the root, and edges are listed in the same order.
+func (t *Tree[K, V]) GraphExport() ([]GraphNode[K], []GraphEdge) {
+	var nodes []GraphNode[K]
+	var edges []GraphEdge
+
+	queue := t.rootLevel()
+	for id := 0; id < len(queue); id++ {
+		n := queue[id]
+
+		keys := make([]K, len(n.Elements))
+		for i, e := range n.Elements {
+			keys[i] = e.Key
+		}
+		nodes = append(nodes, GraphNode[K]{ID: id, Keys: keys})
+
+		for _, c := range n.Children {
+			edges = append(edges, GraphEdge{From: id, To: len(queue)})
+			queue = append(queue, c)
+		}
+	}
+
+	return nodes, edges
+}
+
+// dotEscaper escapes the characters that are special in Graphviz record
+// labels
+var dotEscaper = strings.NewReplacer(
+	`\`, `\\`, `"`, `\"`, `|`, `\|`, `{`, `\{`, `}`, `\}`, `<`, `\<`, `>`, `\>`,
+)
+
+// WriteDot writes the tree to w as a Graphviz digraph, for rendering with
+// e.g. dot -Tpng. Each node is a record of its keys. Internal nodes also
+// have an empty port field around every key, and each edge leaves the
+// port between the two separators that bound the child. An empty tree is
+// a digraph with no nodes.
+func (t *Tree[K, V]) WriteDot(w io.Writer) {
+	nodes, edges := t.GraphExport()
+
+	children := make(map[int]int)
+	for _, e := range edges {
+		children[e.From]++
+	}
+
+	fmt.Fprintln(w, "digraph ntree {")
+	fmt.Fprintln(w, "\tnode [shape=record];")
+	for _, n := range nodes {
+		var fields []string
+		for i, key := range n.Keys {
+			if children[n.ID] > 0 {
+				fields = append(fields, fmt.Sprintf("<c%d>", i))
+			}
+			fields = append(fields, dotEscaper.Replace(fmt.Sprint(key)))
+		}
+		if children[n.ID] > 0 {
+			fields = append(fields, fmt.Sprintf("<c%d>", len(n.Keys)))
+		}
+
+		fmt.Fprintf(w, "\tn%d [label=\"%s\"];\n", n.ID, strings.Join(fields, "|"))
+	}
+
+	port := make(map[int]int)
+	for _, e := range edges {
+		fmt.Fprintf(w, "\tn%d:c%d -> n%d;\n", e.From, port[e.From], e.To)
+		port[e.From]++
+	}
+	fmt.Fprintln(w, "}")
+}
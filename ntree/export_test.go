@@ -0,0 +1,60 @@
+package ntree
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphExport(t *testing.T) {
+	tr := exampleTree()
+
+	nodes, edges := tr.GraphExport()
+	assert.Len(t, nodes, 4, "root and three leaves")
+	assert.Len(t, edges, len(nodes)-1, "every node but the root has one incoming edge")
+	assert.Equal(t, []int{3, 6}, nodes[0].Keys, "root should be exported first")
+
+	ids := map[int]bool{}
+	for _, n := range nodes {
+		assert.False(t, ids[n.ID], "node IDs should be unique")
+		ids[n.ID] = true
+	}
+	for _, e := range edges {
+		assert.True(t, ids[e.From], "edge should start at a known node")
+		assert.True(t, ids[e.To], "edge should end at a known node")
+		assert.Equal(t, 0, e.From, "all edges leave the root in a two-level tree")
+	}
+
+	nodes, edges = New[int, string](3).GraphExport()
+	assert.Empty(t, nodes, "empty tree has no nodes")
+	assert.Empty(t, edges, "empty tree has no edges")
+}
+
+func TestWriteDot(t *testing.T) {
+	tr := exampleTree()
+
+	var buf bytes.Buffer
+	tr.WriteDot(&buf)
+	assert.Equal(t, `digraph ntree {
+	node [shape=record];
+	n0 [label="<c0>|3|<c1>|6|<c2>"];
+	n1 [label="1|2"];
+	n2 [label="4|5"];
+	n3 [label="7|8|9"];
+	n0:c0 -> n1;
+	n0:c1 -> n2;
+	n0:c2 -> n3;
+}
+`, buf.String(), "each node should be a record and each child hang off its port")
+
+	words := New[string, int](3)
+	words.Put("a|b", 1)
+	buf.Reset()
+	words.WriteDot(&buf)
+	assert.Contains(t, buf.String(), `n0 [label="a\|b"];`, "record separators in keys should be escaped")
+
+	buf.Reset()
+	New[int, int](3).WriteDot(&buf)
+	assert.Equal(t, "digraph ntree {\n\tnode [shape=record];\n}\n", buf.String(), "an empty tree has no nodes")
+}
@@ -0,0 +1,45 @@
+package ntree
+
+import "slices"
+
+// FrozenTree is a read-only, compacted copy of a Tree. Its keys and values
+// are kept in flat sorted arrays so lookups are a single binary search
+// without any pointer chasing.
+type FrozenTree[K comparable, V any] struct {
+	keys       []K
+	values     []V
+	comparator func(x, y K) int
+}
+
+// Freeze returns a read-only snapshot of the tree. Later changes to the
+// tree are not reflected in the snapshot.
+func (t *Tree[K, V]) Freeze() *FrozenTree[K, V] {
+	f := &FrozenTree[K, V]{
+		keys:       make([]K, 0, t.size),
+		values:     make([]V, 0, t.size),
+		comparator: t.Comparator,
+	}
+
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		f.keys = append(f.keys, e.Key)
+		f.values = append(f.values, e.Value)
+		return true
+	})
+
+	return f
+}
+
+// Get retrieves the value associated with the key
+func (f *FrozenTree[K, V]) Get(key K) (value V, found bool) {
+	i, found := slices.BinarySearchFunc(f.keys, key, f.comparator)
+	if !found {
+		return value, false
+	}
+
+	return f.values[i], true
+}
+
+// Size returns the number of keys in the frozen tree
+func (f *FrozenTree[K, V]) Size() int {
+	return len(f.keys)
+}
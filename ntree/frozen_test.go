@@ -0,0 +1,27 @@
+package ntree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreeze(t *testing.T) {
+	tr := exampleTree()
+	f := tr.Freeze()
+
+	assert.Equal(t, tr.Size(), f.Size(), "frozen size should match")
+	for key := 1; key <= 9; key++ {
+		want, _ := tr.Get(key)
+		got, found := f.Get(key)
+		assert.True(t, found, "frozen tree should hold every key")
+		assert.Equal(t, want, got, "frozen lookups should match the tree")
+	}
+
+	_, found := f.Get(10)
+	assert.False(t, found, "absent key should not be found")
+
+	tr.Put(10, "j")
+	_, found = f.Get(10)
+	assert.False(t, found, "frozen tree should not see later writes")
+}
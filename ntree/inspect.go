@@ -0,0 +1,318 @@
+package ntree
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// LevelNodeGroups returns the keys of the tree level by level, keeping the
+// keys of each node together: element [i][j] holds the keys of the j-th
+// node, from left to right, at depth i.
+func (t *Tree[K, V]) LevelNodeGroups() [][][]K {
+	var levels [][][]K
+	for level := t.rootLevel(); len(level) > 0; level = t.nextLevel(level) {
+		groups := make([][]K, 0, len(level))
+		for _, n := range level {
+			keys := make([]K, len(n.Elements))
+			for i, e := range n.Elements {
+				keys[i] = e.Key
+			}
+			groups = append(groups, keys)
+		}
+		levels = append(levels, groups)
+	}
+
+	return levels
+}
+
+// NodeCount returns the number of nodes in the tree
+func (t *Tree[K, V]) NodeCount() int {
+	return t.Root.Size()
+}
+
+// rootLevel returns the nodes at depth 0, which is empty for an empty tree.
+func (t *Tree[K, V]) rootLevel() []*Node[K, V] {
+	if t.Root == nil {
+		return nil
+	}
+
+	return []*Node[K, V]{t.Root}
+}
+
+// nextLevel returns the children of the given nodes, from left to right.
+func (t *Tree[K, V]) nextLevel(level []*Node[K, V]) []*Node[K, V] {
+	var next []*Node[K, V]
+	for _, n := range level {
+		next = append(next, n.Children...)
+	}
+
+	return next
+}
+
+// WorstCaseComparisons returns an upper bound on the number of key
+// comparisons a single lookup can need: one binary search over at most
+// m-1 keys, costing ceil(log2(m)) comparisons, per level of the tree.
+func (t *Tree[K, V]) WorstCaseComparisons() int {
+	return t.Height() * bits.Len(uint(t.m-1))
+}
+
+// LevelSizes returns the number of elements stored at each depth, with the
+// root's level first. Height counts levels, so the result has Height
+// entries.
+func (t *Tree[K, V]) LevelSizes() []int {
+	var sizes []int
+	for level := t.rootLevel(); len(level) > 0; level = t.nextLevel(level) {
+		size := 0
+		for _, n := range level {
+			size += len(n.Elements)
+		}
+		sizes = append(sizes, size)
+	}
+
+	return sizes
+}
+
+// WouldRebalanceOnRemove reports whether removing key would force a borrow
+// from a sibling or a merge, because the leaf that loses an element is
+// already at minimum fill. Removing from an internal node takes the
+// in-order predecessor from its leaf, so that leaf is the one checked.
+// The tree is not modified; absent keys report false.
+func (t *Tree[K, V]) WouldRebalanceOnRemove(key K) bool {
+	n, index, found := t.searchRecursive(t.Root, key)
+	if !found {
+		return false
+	}
+
+	if !t.isLeaf(n) {
+		n = n.Children[index]
+		for !t.isLeaf(n) {
+			n = n.Children[len(n.Children)-1]
+		}
+	}
+
+	return !t.isRoot(n) && len(n.Elements) <= t.minElements()
+}
+
+// SplitPreview reports how a split of n would partition its keys: the keys
+// kept in the left node, the median moved up to the parent, and the keys
+// moved to the right node. wouldSplit reports whether n is overfull, so
+// that an insert would actually split it. Nothing is partitioned for a
+// node too small to have a median at the split point.
+func (t *Tree[K, V]) SplitPreview(n *Node[K, V]) (leftKeys []K, medianKey K, rightKeys []K, wouldSplit bool) {
+	mid := (t.m - 1) / 2
+	if n == nil || len(n.Elements) <= mid {
+		return nil, medianKey, nil, false
+	}
+
+	for _, e := range n.Elements[:mid] {
+		leftKeys = append(leftKeys, e.Key)
+	}
+	for _, e := range n.Elements[mid+1:] {
+		rightKeys = append(rightKeys, e.Key)
+	}
+
+	return leftKeys, n.Elements[mid].Key, rightKeys, t.shouldSplit(n)
+}
+
+// Stats returns the size, minimum and maximum keys, and height of the tree
+// in one call. Size and height are cheap; the extremes take one descent
+// each. ok is false when the tree is empty.
+func (t *Tree[K, V]) Stats() (size int, minKey, maxKey K, height int, ok bool) {
+	minKey, maxKey, ok = t.Span()
+	return t.size, minKey, maxKey, t.Height(), ok
+}
+
+// NodeStats describes how the elements of a tree are spread over its
+// nodes.
+type NodeStats struct {
+	InternalNodes int
+	Leaves        int
+	Nodes         int
+
+	// AverageFill is the mean over all nodes of the number of elements
+	// divided by m-1, the most a node can hold
+	AverageFill float64
+}
+
+// NodeStats counts the internal nodes and leaves of the tree and their
+// average fill in a single pass over the nodes. Everything is zero for an
+// empty tree. It is separate from Stats, which reports size, span and
+// height.
+func (t *Tree[K, V]) NodeStats() NodeStats {
+	var stats NodeStats
+	elements := 0
+	for level := t.rootLevel(); len(level) > 0; level = t.nextLevel(level) {
+		for _, n := range level {
+			if t.isLeaf(n) {
+				stats.Leaves++
+			} else {
+				stats.InternalNodes++
+			}
+			elements += len(n.Elements)
+		}
+	}
+
+	stats.Nodes = stats.InternalNodes + stats.Leaves
+	if stats.Nodes > 0 {
+		stats.AverageFill = float64(elements) / float64(stats.Nodes*t.maxElements())
+	}
+
+	return stats
+}
+
+// CapacityBounds returns the fewest and the most keys a valid B-tree of
+// order m and the tree's current height can hold. The root needs only one
+// key and two children, every other node ceil(m/2)-1 keys, so a minimal
+// tree of height h holds 2*ceil(m/2)^(h-1)-1 keys, while a full one holds
+// m^h-1.
+func (t *Tree[K, V]) CapacityBounds() (min, max int) {
+	h := t.Height()
+	if h == 0 {
+		return 0, 0
+	}
+
+	half := t.minElements() + 1
+	min, max = 1, t.maxElements()
+	for level := 1; level < h; level++ {
+		min = min*half + half - 1
+		max = max*t.m + t.maxElements()
+	}
+
+	return min, max
+}
+
+// IsBalanced reports whether every leaf is at the same depth, in a single
+// traversal. It is a lighter check than Validate, limited to balance.
+func (t *Tree[K, V]) IsBalanced() bool {
+	leafDepth := -1
+	var balanced func(n *Node[K, V], depth int) bool
+	balanced = func(n *Node[K, V], depth int) bool {
+		if t.isLeaf(n) {
+			if leafDepth < 0 {
+				leafDepth = depth
+			}
+			return leafDepth == depth
+		}
+
+		for _, c := range n.Children {
+			if !balanced(c, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return t.Root == nil || balanced(t.Root, 0)
+}
+
+// Validate checks the B-tree invariants and returns an error describing the
+// first violation, or nil if the tree is healthy. It checks that every
+// non-root node holds between ceil(m/2)-1 and m-1 keys, the keys of each
+// node are sorted and fall between the separators of its parent, internal
+// nodes have one more child than keys, all leaves are at the same depth,
+// and parent pointers, cached subtree sizes and Size agree with the nodes.
+func (t *Tree[K, V]) Validate() error {
+	if t.Root == nil {
+		if t.size != 0 {
+			return fmt.Errorf("ntree: empty tree reports size %d", t.size)
+		}
+		return nil
+	}
+
+	if t.Root.Parent != nil {
+		return fmt.Errorf("ntree: root has a parent")
+	}
+
+	if len(t.Root.Elements) == 0 {
+		return fmt.Errorf("ntree: root has no elements")
+	}
+
+	v := validator[K, V]{t: t, leafDepth: -1}
+	count, err := v.check(t.Root, 0, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if count != t.size {
+		return fmt.Errorf("ntree: tree holds %d elements but reports size %d", count, t.size)
+	}
+
+	return nil
+}
+
+type validator[K comparable, V any] struct {
+	t         *Tree[K, V]
+	leafDepth int
+}
+
+// check validates the subtree rooted at n, whose keys must lie strictly
+// between lo and hi when those are set, and returns its element count.
+func (v *validator[K, V]) check(n *Node[K, V], depth int, lo, hi *Element[K, V]) (int, error) {
+	t := v.t
+	if !t.isRoot(n) && (len(n.Elements) < t.minElements() || len(n.Elements) > t.maxElements()) {
+		return 0, fmt.Errorf("ntree: node at depth %d has %d elements, want between %d and %d",
+			depth, len(n.Elements), t.minElements(), t.maxElements())
+	}
+
+	if len(n.Elements) > t.maxElements() {
+		return 0, fmt.Errorf("ntree: root has %d elements, want at most %d", len(n.Elements), t.maxElements())
+	}
+
+	for i, e := range n.Elements {
+		if i > 0 && t.Comparator(n.Elements[i-1].Key, e.Key) >= 0 {
+			return 0, fmt.Errorf("ntree: keys %v and %v at depth %d are not in ascending order",
+				n.Elements[i-1].Key, e.Key, depth)
+		}
+	}
+
+	if len(n.Elements) > 0 {
+		first, last := n.Elements[0], n.Elements[len(n.Elements)-1]
+		if lo != nil && t.Comparator(first.Key, lo.Key) <= 0 {
+			return 0, fmt.Errorf("ntree: key %v at depth %d is not greater than separator %v", first.Key, depth, lo.Key)
+		}
+		if hi != nil && t.Comparator(last.Key, hi.Key) >= 0 {
+			return 0, fmt.Errorf("ntree: key %v at depth %d is not less than separator %v", last.Key, depth, hi.Key)
+		}
+	}
+
+	count := len(n.Elements)
+	if t.isLeaf(n) {
+		if v.leafDepth < 0 {
+			v.leafDepth = depth
+		} else if v.leafDepth != depth {
+			return 0, fmt.Errorf("ntree: leaves at depths %d and %d", v.leafDepth, depth)
+		}
+	} else {
+		if len(n.Children) != len(n.Elements)+1 {
+			return 0, fmt.Errorf("ntree: node at depth %d has %d elements but %d children",
+				depth, len(n.Elements), len(n.Children))
+		}
+
+		for i, c := range n.Children {
+			if c.Parent != n {
+				return 0, fmt.Errorf("ntree: child %d of node at depth %d has a wrong parent", i, depth)
+			}
+
+			clo, chi := lo, hi
+			if i > 0 {
+				clo = n.Elements[i-1]
+			}
+			if i < len(n.Elements) {
+				chi = n.Elements[i]
+			}
+
+			size, err := v.check(c, depth+1, clo, chi)
+			if err != nil {
+				return 0, err
+			}
+			count += size
+		}
+	}
+
+	if n.subtreeSize != count {
+		return 0, fmt.Errorf("ntree: node at depth %d caches subtree size %d but holds %d elements",
+			depth, n.subtreeSize, count)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,197 @@
+package ntree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func countLeaves[K comparable, V any](n *Node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+
+	if len(n.Children) == 0 {
+		return 1
+	}
+
+	leaves := 0
+	for _, c := range n.Children {
+		leaves += countLeaves(c)
+	}
+
+	return leaves
+}
+
+func TestLevelNodeGroups(t *testing.T) {
+	tr := exampleTree()
+
+	levels := tr.LevelNodeGroups()
+	assert.Len(t, levels, tr.Height(), "there should be one entry per level")
+	assert.Equal(t, [][]int{{3, 6}}, levels[0], "level 0 should hold only the root's keys")
+	assert.Len(t, levels[len(levels)-1], countLeaves(tr.Root), "deepest level should have one group per leaf")
+	assert.Equal(t, [][]int{{1, 2}, {4, 5}, {7, 8, 9}}, levels[1], "leaf keys should stay grouped by node")
+
+	assert.Empty(t, New[int, string](3).LevelNodeGroups(), "empty tree has no levels")
+}
+
+func TestWorstCaseComparisons(t *testing.T) {
+	tr := exampleTree()
+
+	// height 2, m = 5: ceil(log2(5)) = 3 comparisons per node
+	assert.Equal(t, 6, tr.WorstCaseComparisons(), "worst case should be 2 * 3")
+	assert.Equal(t, 0, New[int, string](5).WorstCaseComparisons(), "empty tree needs no comparisons")
+}
+
+func TestLevelSizes(t *testing.T) {
+	tr := exampleTree()
+
+	sizes := tr.LevelSizes()
+	assert.Equal(t, []int{2, 7}, sizes, "root holds 2 keys and the leaves 7")
+	assert.Len(t, sizes, tr.Height(), "there should be one entry per level")
+
+	total := 0
+	for _, s := range sizes {
+		total += s
+	}
+	assert.Equal(t, tr.Size(), total, "level sizes should add up to Size")
+}
+
+func TestWouldRebalanceOnRemove(t *testing.T) {
+	tr := exampleTree()
+
+	// leaves are [1 2], [4 5] and [7 8 9]; m = 5 needs 2 keys per leaf
+	assert.True(t, tr.WouldRebalanceOnRemove(1), "leaf [1 2] is at minimum fill")
+	assert.True(t, tr.WouldRebalanceOnRemove(3), "removing 3 takes predecessor 2 from a minimum leaf")
+	assert.False(t, tr.WouldRebalanceOnRemove(8), "leaf [7 8 9] has a spare key")
+	assert.False(t, tr.WouldRebalanceOnRemove(42), "absent key would not remove anything")
+
+	size := tr.Size()
+	tr.WouldRebalanceOnRemove(1)
+	assert.Equal(t, size, tr.Size(), "prediction should not mutate the tree")
+}
+
+func TestSplitPreview(t *testing.T) {
+	tr := New[int, string](5)
+	for key := 1; key <= 4; key++ {
+		tr.Put(key, "")
+	}
+
+	_, _, _, wouldSplit := tr.SplitPreview(tr.Root)
+	assert.False(t, wouldSplit, "a full node does not split yet")
+
+	// overfill the root by hand to m elements, as an insert does just before splitting
+	tr.Root.Elements = append(tr.Root.Elements, &Element[int, string]{Key: 5})
+	left, median, right, wouldSplit := tr.SplitPreview(tr.Root)
+	assert.True(t, wouldSplit, "a node with m elements should split")
+
+	tr.split(tr.Root)
+	assert.Equal(t, []int{median}, tr.LevelNodeGroups()[0][0], "median should move up to the new root")
+	assert.Equal(t, [][]int{left, right}, tr.LevelNodeGroups()[1], "halves should match the real split")
+}
+
+func TestNodeStats(t *testing.T) {
+	tr := exampleTree()
+
+	stats := tr.NodeStats()
+	assert.Equal(t, 1, stats.InternalNodes, "only the root is internal")
+	assert.Equal(t, 3, stats.Leaves, "three leaves hang off the root")
+	assert.Equal(t, tr.NodeCount(), stats.Nodes, "total should match NodeCount")
+	assert.InDelta(t, 9.0/16, stats.AverageFill, 1e-9, "9 elements over 4 nodes of 4 slots")
+
+	full := New[int, int](5)
+	full.ReplaceAll([]Element[int, int]{{1, 1}, {2, 2}, {3, 3}, {4, 4}})
+	assert.Equal(t, NodeStats{Leaves: 1, Nodes: 1, AverageFill: 1}, full.NodeStats(), "a full leaf root")
+
+	assert.Equal(t, NodeStats{}, New[int, int](3).NodeStats(), "an empty tree reports zeros")
+}
+
+func TestStats(t *testing.T) {
+	tr := exampleTree()
+
+	size, minKey, maxKey, height, ok := tr.Stats()
+	spanMin, spanMax, _ := tr.Span()
+	assert.True(t, ok, "stats should be available for a non-empty tree")
+	assert.Equal(t, tr.Size(), size, "size should match Size")
+	assert.Equal(t, spanMin, minKey, "min should match Span")
+	assert.Equal(t, spanMax, maxKey, "max should match Span")
+	assert.Equal(t, tr.Height(), height, "height should match Height")
+
+	size, _, _, height, ok = New[int, string](3).Stats()
+	assert.False(t, ok, "empty tree has no extremes")
+	assert.Equal(t, 0, size, "empty tree has size 0")
+	assert.Equal(t, 0, height, "empty tree has height 0")
+}
+
+func TestCapacityBounds(t *testing.T) {
+	tr := exampleTree()
+
+	// height 2, m = 5: at least 2*3-1 keys, at most 5*5-1
+	min, max := tr.CapacityBounds()
+	assert.Equal(t, 5, min, "minimal two-level tree of order 5")
+	assert.Equal(t, 24, max, "full two-level tree of order 5")
+	assert.True(t, tr.Size() >= min && tr.Size() <= max, "size should fall within the bounds")
+
+	for key := 10; key <= 100; key++ {
+		tr.Put(key, "")
+	}
+	assert.Greater(t, tr.Height(), 2, "tree should have grown taller")
+
+	tallMin, tallMax := tr.CapacityBounds()
+	assert.Greater(t, tallMin, min, "lower bound should grow with height")
+	assert.Greater(t, tallMax, max, "upper bound should grow with height")
+	assert.True(t, tr.Size() >= tallMin && tr.Size() <= tallMax, "size should fall within the bounds")
+
+	min, max = New[int, string](5).CapacityBounds()
+	assert.Equal(t, 0, min, "empty tree holds nothing")
+	assert.Equal(t, 0, max, "empty tree holds nothing")
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, New[int, string](3).Validate(), "empty tree is valid")
+	assert.NoError(t, exampleTree().Validate(), "example tree is valid")
+
+	for _, m := range []int{3, 4, 5, 8} {
+		tr := New[int, int](m)
+		for i := 0; i < 1000; i++ {
+			tr.Put((i*7919)%2000, i)
+			if i%3 == 0 {
+				tr.Remove((i * 104729) % 2000)
+			}
+		}
+		assert.NoError(t, tr.Validate(), "m=%d: tree should be valid after many mutations", m)
+	}
+
+	tr := exampleTree()
+	tr.Root.Children[0].Elements = tr.Root.Children[0].Elements[:1]
+	assert.ErrorContains(t, tr.Validate(), "has 1 elements, want between 2 and 4", "underfull leaf should be reported")
+
+	tr = exampleTree()
+	tr.Root.Children[1].Elements[0].Key = 1
+	assert.ErrorContains(t, tr.Validate(), "not greater than separator 3", "misplaced key should be reported")
+
+	tr = exampleTree()
+	tr.Root.Children[2].Elements[0], tr.Root.Children[2].Elements[1] =
+		tr.Root.Children[2].Elements[1], tr.Root.Children[2].Elements[0]
+	assert.ErrorContains(t, tr.Validate(), "not in ascending order", "unsorted node should be reported")
+
+	tr = exampleTree()
+	tr.Root.Children = tr.Root.Children[:2]
+	assert.ErrorContains(t, tr.Validate(), "2 elements but 2 children", "missing child should be reported")
+
+	tr = exampleTree()
+	tr.size++
+	assert.ErrorContains(t, tr.Validate(), "reports size 10", "wrong size should be reported")
+}
+
+func TestIsBalanced(t *testing.T) {
+	assert.True(t, New[int, string](3).IsBalanced(), "empty tree is balanced")
+	assert.True(t, exampleTree().IsBalanced(), "example tree is balanced")
+
+	tr := exampleTree()
+	leaf := tr.Root.Children[2]
+	between := &Node[int, string]{Parent: tr.Root, Children: []*Node[int, string]{leaf}}
+	leaf.Parent = between
+	tr.Root.Children[2] = between
+	assert.False(t, tr.IsBalanced(), "a leaf re-parented one level deeper breaks balance")
+}
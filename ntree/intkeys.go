@@ -0,0 +1,109 @@
+package ntree
+
+// EveryStep returns, in ascending order, the elements whose keys are
+// congruent to the minimum key modulo step, downsampling the tree by key
+// value. It returns nil when step is not positive.
+//
+// Go does not allow methods on an instantiated receiver such as
+// *Tree[int, V], so the integer-key helpers in this file are functions.
+// They step through keys with integer arithmetic, so they assume t orders
+// its keys naturally, as New does; a custom Comparator gives wrong results.
+func EveryStep[V any](t *Tree[int, V], step int) []Element[int, V] {
+	lo := t.minElement()
+	if step < 1 || lo == nil {
+		return nil
+	}
+
+	var sampled []Element[int, V]
+	t.walk(t.Root, func(e *Element[int, V]) bool {
+		if (e.Key-lo.Key)%step == 0 {
+			sampled = append(sampled, *e)
+		}
+		return true
+	})
+
+	return sampled
+}
+
+// KeyRanges returns the keys of t as inclusive [start, end] ranges of
+// consecutive integers, in ascending order.
+func KeyRanges[V any](t *Tree[int, V]) [][2]int {
+	var ranges [][2]int
+	t.walk(t.Root, func(e *Element[int, V]) bool {
+		last := len(ranges) - 1
+		if last >= 0 && ranges[last][1]+1 == e.Key {
+			ranges[last][1] = e.Key
+		} else {
+			ranges = append(ranges, [2]int{e.Key, e.Key})
+		}
+		return true
+	})
+
+	return ranges
+}
+
+// ToBitset packs the keys of t into a bitset: bit i of the result, counting
+// from the low bit of bits[0], is set when base+i is a key. base is the
+// minimum key. The bitset spans the whole key range, so this is only
+// practical for dense integer key spaces; sparse keys waste memory.
+func ToBitset[V any](t *Tree[int, V]) (base int, bits []uint64) {
+	lo, hi := t.minElement(), t.maxElement()
+	if lo == nil {
+		return 0, nil
+	}
+
+	base = lo.Key
+	bits = make([]uint64, (hi.Key-base)/64+1)
+	t.walk(t.Root, func(e *Element[int, V]) bool {
+		offset := e.Key - base
+		bits[offset/64] |= 1 << (offset % 64)
+		return true
+	})
+
+	return base, bits
+}
+
+// BitsetContains reports whether key is marked in a bitset built by
+// ToBitset.
+func BitsetContains(base int, bits []uint64, key int) bool {
+	offset := key - base
+	if offset < 0 || offset/64 >= len(bits) {
+		return false
+	}
+
+	return bits[offset/64]&(1<<(offset%64)) != 0
+}
+
+// MissingInRange returns, in ascending order, every integer in [lo, hi]
+// that is not a key of t. Only the keys inside the range are visited, and
+// the walk stops at hi without stepping past it, so ranges ending at
+// math.MaxInt are safe.
+func MissingInRange[V any](t *Tree[int, V], lo, hi int) []int {
+	var missing []int
+	if lo > hi {
+		return missing
+	}
+
+	c := newCursor(t)
+	c.seek(lo)
+
+	next := lo
+	for e := c.next(); e != nil && e.Key <= hi; e = c.next() {
+		for ; next < e.Key; next++ {
+			missing = append(missing, next)
+		}
+
+		if e.Key == hi {
+			return missing
+		}
+		next = e.Key + 1
+	}
+
+	for {
+		missing = append(missing, next)
+		if next == hi {
+			return missing
+		}
+		next++
+	}
+}
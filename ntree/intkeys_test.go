@@ -0,0 +1,79 @@
+package ntree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func elementKeys[K comparable, V any](elements []Element[K, V]) []K {
+	keys := make([]K, len(elements))
+	for i, e := range elements {
+		keys[i] = e.Key
+	}
+
+	return keys
+}
+
+func TestEveryStep(t *testing.T) {
+	tr := exampleTree()
+
+	assert.Equal(t, []int{1, 4, 7}, elementKeys(EveryStep(tr, 3)), "every third key from the min")
+	assert.Equal(t, 9, len(EveryStep(tr, 1)), "step 1 keeps every key")
+	assert.Nil(t, EveryStep(tr, 0), "non-positive step returns nothing")
+}
+
+func TestKeyRanges(t *testing.T) {
+	tr := New[int, string](3)
+	for _, k := range []int{9, 1, 5, 2, 6, 3} {
+		tr.Put(k, "")
+	}
+
+	assert.Equal(t, [][2]int{{1, 3}, {5, 6}, {9, 9}}, KeyRanges(tr), "consecutive keys should merge into ranges")
+	assert.Empty(t, KeyRanges(New[int, string](3)), "empty tree has no ranges")
+}
+
+func TestToBitset(t *testing.T) {
+	tr := New[int, string](3)
+	for _, k := range []int{1, 3, 5, 130} {
+		tr.Put(k, "")
+	}
+
+	base, bits := ToBitset(tr)
+	assert.Equal(t, 1, base, "base should be the minimum key")
+	assert.Len(t, bits, 3, "130 keys past the base need three words")
+
+	for key := -1; key <= 200; key++ {
+		_, found := tr.Get(key)
+		assert.Equal(t, found, BitsetContains(base, bits, key), "bitset membership should match the tree")
+	}
+
+	base, bits = ToBitset(New[int, string](3))
+	assert.False(t, BitsetContains(base, bits, 0), "empty bitset contains nothing")
+}
+
+func TestMissingInRange(t *testing.T) {
+	tr := New[int, string](3)
+	for _, k := range []int{2, 4, 5} {
+		tr.Put(k, "")
+	}
+
+	assert.Equal(t, []int{1, 3, 6}, MissingInRange(tr, 1, 6), "gaps in [1, 6] should be reported")
+	assert.Empty(t, MissingInRange(tr, 4, 5), "a fully covered range has no gaps")
+	assert.Equal(t, []int{7, 8}, MissingInRange(tr, 7, 8), "a range beyond the keys is all gaps")
+	assert.Empty(t, MissingInRange(tr, 6, 1), "an inverted range is empty")
+
+	edge := New[int, string](3)
+	edge.Put(math.MaxInt-1, "")
+	assert.Equal(t, []int{math.MaxInt - 2, math.MaxInt}, MissingInRange(edge, math.MaxInt-2, math.MaxInt), "a range ending at MaxInt should stop there")
+
+	edge.Put(math.MaxInt, "")
+	assert.Equal(t, []int{math.MaxInt - 2}, MissingInRange(edge, math.MaxInt-2, math.MaxInt), "a MaxInt key should not wrap to MinInt")
+	assert.Empty(t, MissingInRange(edge, math.MaxInt, math.MaxInt), "a covered single-key range at MaxInt has no gaps")
+	assert.Equal(t, []int{math.MinInt, math.MinInt + 1}, MissingInRange(edge, math.MinInt, math.MinInt+1), "a range at MinInt is all gaps")
+
+	desc, err := BuildFromSorted(3, []int{5, 3, 1}, []string{"", "", ""}, func(a, b int) int { return b - a })
+	assert.NoError(t, err)
+	assert.Empty(t, MissingInRange(desc, 6, 0), "bounds are compared as integers whatever the comparator")
+}
@@ -0,0 +1,311 @@
+package ntree
+
+import (
+	"errors"
+	"iter"
+	"strings"
+)
+
+// Iterator walks the elements of a tree in key order. Call Next to advance
+// to each element before reading it with Key and Value. Modifying the tree
+// invalidates the iterator: Next panics unless Seek repositions it first.
+type Iterator[K comparable, V any] struct {
+	c       *cursor[K, V]
+	current *Element[K, V]
+	past    func(K) bool // reports whether a key lies beyond the end
+	before  func(K) bool // reports whether a key lies below lo
+	lo      K            // smallest key in bounds when before is set
+	reverse bool         // walk in descending key order
+}
+
+// Iterator returns an iterator over all elements in ascending key order
+func (t *Tree[K, V]) Iterator() *Iterator[K, V] {
+	return &Iterator[K, V]{c: newCursor(t)}
+}
+
+// ReverseIterator returns an iterator over all elements in descending key
+// order
+func (t *Tree[K, V]) ReverseIterator() *Iterator[K, V] {
+	c := openCursor(t)
+	c.pushRight(t.Root)
+	return &Iterator[K, V]{c: c, reverse: true}
+}
+
+// Range returns an iterator over the keys k with lo <= k < hi in ascending
+// order. It starts with a descent to the ceiling of lo, so no element below
+// lo is visited. lo >= hi yields nothing.
+func (t *Tree[K, V]) Range(lo, hi K) *Iterator[K, V] {
+	it := &Iterator[K, V]{
+		c:      openCursor(t),
+		past:   func(key K) bool { return t.Comparator(key, hi) >= 0 },
+		before: func(key K) bool { return t.Comparator(key, lo) < 0 },
+		lo:     lo,
+	}
+
+	if t.Comparator(lo, hi) < 0 {
+		it.c.seek(lo)
+	}
+
+	return it
+}
+
+// PrefixScan returns an iterator over the keys of t that begin with prefix,
+// in ascending order. Under the default byte-wise order those keys form one
+// contiguous run, so the iterator starts with a descent to the ceiling of
+// prefix and stops at the first key past the run. With another Comparator
+// the matching keys need not be contiguous, and the scan stops at the
+// first key after prefix that does not match. It is a function rather than
+// a method because it only applies to string keys.
+func PrefixScan[V any](t *Tree[string, V], prefix string) *Iterator[string, V] {
+	it := &Iterator[string, V]{
+		c:      openCursor(t),
+		past:   func(key string) bool { return !strings.HasPrefix(key, prefix) },
+		before: func(key string) bool { return t.Comparator(key, prefix) < 0 },
+		lo:     prefix,
+	}
+
+	it.c.seek(prefix)
+	return it
+}
+
+// Next advances the iterator to the next element, reporting whether there
+// is one.
+func (it *Iterator[K, V]) Next() bool {
+	if it.reverse {
+		it.current = it.c.prev()
+	} else {
+		it.current = it.c.next()
+	}
+
+	if it.current != nil && !it.inBounds(it.current.Key) {
+		it.current = nil
+		it.c.stack = it.c.stack[:0]
+	}
+
+	return it.current != nil
+}
+
+// Seek repositions the iterator so that the following Next yields the
+// smallest key >= key, or the largest key <= key for a reverse iterator,
+// reporting whether such a key exists within the iterator's bounds. A
+// forward seek below the start of a Range or PrefixScan starts at its
+// lower bound instead. The path is rebuilt by a single descent from the
+// root, whatever the current position.
+func (it *Iterator[K, V]) Seek(key K) bool {
+	it.current = nil
+
+	var e *Element[K, V]
+	if it.reverse {
+		it.c.seekBack(key)
+		e = it.c.peekBack()
+	} else {
+		if it.before != nil && it.before(key) {
+			key = it.lo
+		}
+		it.c.seek(key)
+		e = it.c.peek()
+	}
+
+	return e != nil && it.inBounds(e.Key)
+}
+
+// inBounds reports whether key lies within the iterator's bounds.
+func (it *Iterator[K, V]) inBounds(key K) bool {
+	return (it.past == nil || !it.past(key)) && (it.before == nil || !it.before(key))
+}
+
+// Key returns the key of the current element
+func (it *Iterator[K, V]) Key() K {
+	return it.current.Key
+}
+
+// Value returns the value of the current element
+func (it *Iterator[K, V]) Value() V {
+	return it.current.Value
+}
+
+// frame is a node on a cursor's path from the root, with the index of the
+// next element to visit in it.
+type frame[K comparable, V any] struct {
+	n *Node[K, V]
+	i int
+}
+
+// cursor walks the elements of a tree in key order, keeping the path from
+// the root to the current position on an explicit stack. next steps
+// forwards and prev backwards; a cursor is only ever driven one way.
+type cursor[K comparable, V any] struct {
+	t     *Tree[K, V]
+	stack []frame[K, V]
+	mods  int // t.mods when the stack was last built from the root
+}
+
+// errModified is the panic value of a cursor stepped after the tree changed
+var errModified = errors.New("ntree: tree modified during iteration")
+
+// openCursor returns a cursor over t with an empty stack.
+func openCursor[K comparable, V any](t *Tree[K, V]) *cursor[K, V] {
+	return &cursor[K, V]{t: t, mods: t.mods}
+}
+
+// newCursor returns a cursor positioned before the smallest key.
+func newCursor[K comparable, V any](t *Tree[K, V]) *cursor[K, V] {
+	c := openCursor(t)
+	c.pushLeft(t.Root)
+	return c
+}
+
+// check panics if the tree has been modified since the stack was built,
+// as the nodes on it may have been split, merged or dropped.
+func (c *cursor[K, V]) check() {
+	if c.mods != c.t.mods {
+		panic(errModified)
+	}
+}
+
+// pushLeft pushes n and its leftmost descendants down to a leaf.
+func (c *cursor[K, V]) pushLeft(n *Node[K, V]) {
+	for n != nil {
+		c.stack = append(c.stack, frame[K, V]{n: n})
+		if c.t.isLeaf(n) {
+			return
+		}
+
+		n = n.Children[0]
+	}
+}
+
+// next returns the next element in ascending order, or nil once the walk
+// is exhausted.
+func (c *cursor[K, V]) next() *Element[K, V] {
+	c.check()
+	for len(c.stack) > 0 {
+		top := &c.stack[len(c.stack)-1]
+		if top.i < len(top.n.Elements) {
+			n, i := top.n, top.i
+			top.i++
+			if !c.t.isLeaf(n) {
+				c.pushLeft(n.Children[i+1])
+			}
+
+			return n.Elements[i]
+		}
+
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+
+	return nil
+}
+
+// peek returns the element the next call to next will return, without
+// moving the cursor.
+func (c *cursor[K, V]) peek() *Element[K, V] {
+	c.check()
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		if f := c.stack[i]; f.i < len(f.n.Elements) {
+			return f.n.Elements[f.i]
+		}
+	}
+
+	return nil
+}
+
+// seek repositions the cursor so that next returns the smallest key >= key,
+// rebuilding the stack with a single descent from the root. The cursor is
+// valid again afterwards even if the tree has changed.
+func (c *cursor[K, V]) seek(key K) {
+	c.mods = c.t.mods
+	c.stack = c.stack[:0]
+	for n := c.t.Root; n != nil; {
+		ipos, found := c.t.search(n, key)
+		c.stack = append(c.stack, frame[K, V]{n: n, i: ipos})
+		if found || c.t.isLeaf(n) {
+			return
+		}
+
+		n = n.Children[ipos]
+	}
+}
+
+// pushRight pushes n and its rightmost descendants down to a leaf.
+func (c *cursor[K, V]) pushRight(n *Node[K, V]) {
+	for n != nil {
+		c.stack = append(c.stack, frame[K, V]{n: n, i: len(n.Elements) - 1})
+		if c.t.isLeaf(n) {
+			return
+		}
+
+		n = n.Children[len(n.Children)-1]
+	}
+}
+
+// prev returns the next element in descending order, or nil once the walk
+// is exhausted.
+func (c *cursor[K, V]) prev() *Element[K, V] {
+	c.check()
+	for len(c.stack) > 0 {
+		top := &c.stack[len(c.stack)-1]
+		if top.i >= 0 {
+			n, i := top.n, top.i
+			top.i--
+			if !c.t.isLeaf(n) {
+				c.pushRight(n.Children[i])
+			}
+
+			return n.Elements[i]
+		}
+
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+
+	return nil
+}
+
+// peekBack returns the element the next call to prev will return, without
+// moving the cursor.
+func (c *cursor[K, V]) peekBack() *Element[K, V] {
+	c.check()
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		if f := c.stack[i]; f.i >= 0 {
+			return f.n.Elements[f.i]
+		}
+	}
+
+	return nil
+}
+
+// seekBack repositions the cursor so that prev returns the largest key
+// <= key, rebuilding the stack with a single descent from the root.
+func (c *cursor[K, V]) seekBack(key K) {
+	c.mods = c.t.mods
+	c.stack = c.stack[:0]
+	for n := c.t.Root; n != nil; {
+		ipos, found := c.t.search(n, key)
+		if found {
+			c.stack = append(c.stack, frame[K, V]{n: n, i: ipos})
+			return
+		}
+
+		c.stack = append(c.stack, frame[K, V]{n: n, i: ipos - 1})
+		if c.t.isLeaf(n) {
+			return
+		}
+
+		n = n.Children[ipos]
+	}
+}
+
+// DescendFrom returns a sequence of the elements with keys <= start in
+// descending key order. Subtrees holding only larger keys are never
+// visited, and breaking out of the loop stops the walk.
+func (t *Tree[K, V]) DescendFrom(start K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c := openCursor(t)
+		c.seekBack(start)
+		for e := c.prev(); e != nil; e = c.prev() {
+			if !yield(e.Key, e.Value) {
+				return
+			}
+		}
+	}
+}
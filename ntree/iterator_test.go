@@ -0,0 +1,290 @@
+package ntree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescendFrom(t *testing.T) {
+	tr := exampleTree()
+
+	var keys []int
+	for key := range tr.DescendFrom(6) {
+		keys = append(keys, key)
+		if len(keys) == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []int{6, 5, 4}, keys, "descent should start at 6 and honour break")
+
+	keys = nil
+	for key, value := range tr.DescendFrom(100) {
+		keys = append(keys, key)
+		assert.Equal(t, string(rune('a'+key-1)), value, "value should travel with its key")
+	}
+	assert.Equal(t, []int{9, 8, 7, 6, 5, 4, 3, 2, 1}, keys, "a start above the max yields everything")
+
+	keys = nil
+	for key := range tr.DescendFrom(0) {
+		keys = append(keys, key)
+	}
+	assert.Empty(t, keys, "a start below the min yields nothing")
+}
+
+func iteratorKeys[K comparable, V any](it *Iterator[K, V]) []K {
+	var keys []K
+	for it.Next() {
+		keys = append(keys, it.Key())
+	}
+
+	return keys
+}
+
+func TestIterator(t *testing.T) {
+	tr := exampleTree()
+
+	it := tr.Iterator()
+	var keys []int
+	for it.Next() {
+		keys = append(keys, it.Key())
+		assert.Equal(t, string(rune('a'+it.Key()-1)), it.Value(), "value should match its key")
+	}
+	assert.Equal(t, tr.Keys(), keys, "iterator should visit every key in order")
+	assert.False(t, it.Next(), "an exhausted iterator stays exhausted")
+
+	assert.Empty(t, iteratorKeys(New[int, string](3).Iterator()), "empty tree yields nothing")
+}
+
+func TestRangeSeekStaysInBounds(t *testing.T) {
+	tr := exampleTree()
+
+	it := tr.Range(5, 8)
+	assert.True(t, it.Seek(1), "a seek below lo should land on lo")
+	assert.Equal(t, []int{5, 6, 7}, iteratorKeys(it), "nothing below lo should be yielded")
+
+	it = tr.Range(5, 8)
+	assert.True(t, it.Seek(6))
+	assert.Equal(t, []int{6, 7}, iteratorKeys(it), "a seek inside the range is unchanged")
+	assert.False(t, it.Seek(8), "a seek at hi is out of bounds")
+	assert.False(t, tr.Range(7, 3).Seek(1), "an empty range has nothing to seek to")
+
+	words := New[string, int](3)
+	for i, key := range []string{"ant", "app", "apple", "bee"} {
+		words.Put(key, i)
+	}
+	scan := PrefixScan(words, "app")
+	assert.True(t, scan.Seek("a"), "a seek below the prefix should land on it")
+	assert.Equal(t, []string{"app", "apple"}, iteratorKeys(scan))
+
+	rev := tr.ReverseIterator()
+	rev.before, rev.lo = func(key int) bool { return key < 5 }, 5
+	assert.False(t, rev.Seek(3), "a reverse seek below lo is out of bounds")
+	assert.True(t, rev.Seek(7))
+	assert.Equal(t, []int{7, 6, 5}, iteratorKeys(rev), "a reverse walk should stop at lo")
+}
+
+func TestRange(t *testing.T) {
+	tr := exampleTree()
+
+	assert.Equal(t, []int{3, 4, 5, 6}, iteratorKeys(tr.Range(3, 7)), "range should be half-open")
+	assert.Equal(t, []int{1, 2}, iteratorKeys(tr.Range(-10, 3)), "range may start below the min")
+	assert.Equal(t, []int{8, 9}, iteratorKeys(tr.Range(8, 100)), "range may end above the max")
+	assert.Empty(t, iteratorKeys(tr.Range(5, 5)), "an empty interval yields nothing")
+	assert.Empty(t, iteratorKeys(tr.Range(7, 3)), "lo > hi yields nothing")
+
+	tr.Remove(4)
+	assert.Equal(t, []int{5, 6}, iteratorKeys(tr.Range(4, 7)), "range may start at an absent key")
+
+	visited := 0
+	big := New[int, int](4)
+	for key := 0; key < 1000; key++ {
+		big.Put(key, key)
+	}
+	it := big.Range(900, 905)
+	for it.Next() {
+		visited++
+		assert.GreaterOrEqual(t, it.Key(), 900, "no element below lo is visited")
+	}
+	assert.Equal(t, 5, visited, "range should yield exactly the keys in the interval")
+}
+
+func TestIteratorSeek(t *testing.T) {
+	tr := exampleTree()
+	tr.Remove(5)
+
+	it := tr.Iterator()
+	it.Next()
+	it.Next()
+	assert.True(t, it.Seek(5), "a key >= 5 exists")
+	assert.True(t, it.Next(), "Next should follow the seek")
+	assert.Equal(t, 6, it.Key(), "seek to an absent key lands on its ceiling")
+
+	assert.True(t, it.Seek(2), "seeking backwards is allowed")
+	assert.Equal(t, []int{2, 3, 4, 6, 7, 8, 9}, iteratorKeys(it), "iteration resumes from the seek")
+
+	assert.False(t, it.Seek(10), "nothing is >= 10")
+	assert.False(t, it.Next(), "iterator is exhausted after seeking past the max")
+
+	r := tr.Range(2, 7)
+	assert.True(t, r.Seek(4), "4 lies in the range")
+	assert.Equal(t, []int{4, 6}, iteratorKeys(r), "seek within a range keeps its upper bound")
+	assert.False(t, r.Seek(7), "7 lies beyond the range")
+}
+
+func TestReverseIterator(t *testing.T) {
+	tr := exampleTree()
+
+	it := tr.ReverseIterator()
+	assert.Equal(t, []int{9, 8, 7, 6, 5, 4, 3, 2, 1}, iteratorKeys(it), "reverse iteration should descend")
+
+	it = tr.ReverseIterator()
+	assert.True(t, it.Next(), "reverse iterator should yield the max first")
+	assert.Equal(t, "i", it.Value(), "value of the max should be i")
+
+	tr.Remove(5)
+	assert.True(t, it.Seek(5), "a key <= 5 exists")
+	assert.Equal(t, []int{4, 3, 2, 1}, iteratorKeys(it), "reverse seek lands on the floor")
+	assert.False(t, it.Seek(0), "nothing is <= 0")
+
+	assert.Empty(t, iteratorKeys(New[int, string](3).ReverseIterator()), "empty tree yields nothing")
+}
+
+func TestIteratorFailsFastOnModification(t *testing.T) {
+	mutations := map[string]func(tr *Tree[int, string]){
+		"Put new key":  func(tr *Tree[int, string]) { tr.Put(100, "x") },
+		"Put existing": func(tr *Tree[int, string]) { tr.Put(5, "x") },
+		"Remove":       func(tr *Tree[int, string]) { tr.Remove(8) },
+		"PopMin":       func(tr *Tree[int, string]) { tr.PopMin() },
+		"Clear":        func(tr *Tree[int, string]) { tr.Clear() },
+		"Compact":      func(tr *Tree[int, string]) { tr.Compact() },
+		"ReplaceAll":   func(tr *Tree[int, string]) { tr.ReplaceAll([]Element[int, string]{{1, "a"}}) },
+	}
+
+	for name, mutate := range mutations {
+		tr := exampleTree()
+		it := tr.Iterator()
+		assert.True(t, it.Next(), name)
+		mutate(tr)
+		assert.PanicsWithError(t, "ntree: tree modified during iteration", func() { it.Next() }, name)
+
+		tr = exampleTree()
+		rev := tr.ReverseIterator()
+		rev.Next()
+		mutate(tr)
+		assert.PanicsWithError(t, "ntree: tree modified during iteration", func() { rev.Next() }, name)
+	}
+
+	tr := exampleTree()
+	assert.PanicsWithError(t, "ntree: tree modified during iteration", func() {
+		for key := range tr.DescendFrom(9) {
+			tr.Remove(key)
+		}
+	}, "sequences should fail fast too")
+
+	tr = exampleTree()
+	it := tr.Iterator()
+	it.Next()
+	tr.Remove(2)
+	assert.True(t, it.Seek(3), "Seek should reposition an invalidated iterator")
+	assert.Equal(t, []int{3, 4, 5, 6, 7, 8, 9}, iteratorKeys(it), "iteration should resume after Seek")
+
+	it = tr.Iterator()
+	it.Next()
+	tr.Get(5)
+	tr.Contains(6)
+	assert.NotPanics(t, func() { iteratorKeys(it) }, "reads should not invalidate iterators")
+}
+
+func TestPrefixScan(t *testing.T) {
+	tr := New[string, int](3)
+	for i, key := range []string{"apple", "app", "apply", "banana", "ap", "application", "apt", "b", ""} {
+		tr.Put(key, i)
+	}
+
+	assert.Equal(t, []string{"app", "apple", "application", "apply"}, iteratorKeys(PrefixScan(tr, "app")),
+		"keys with the prefix should be yielded in order")
+	assert.Equal(t, []string{"apple"}, iteratorKeys(PrefixScan(tr, "apple")), "an exact key is its own prefix")
+	assert.Equal(t, []string{"b", "banana"}, iteratorKeys(PrefixScan(tr, "b")))
+	assert.Empty(t, iteratorKeys(PrefixScan(tr, "c")), "no key has the prefix")
+	assert.Empty(t, iteratorKeys(PrefixScan(tr, "appz")), "the ceiling of the prefix lacks it")
+	assert.Equal(t, tr.Keys(), iteratorKeys(PrefixScan(tr, "")), "the empty prefix matches everything")
+	assert.Empty(t, iteratorKeys(PrefixScan(New[string, int](3), "a")), "an empty tree has no matches")
+
+	it := PrefixScan(tr, "app")
+	assert.True(t, it.Next())
+	assert.Equal(t, 1, it.Value(), "values should be available")
+
+	fold := func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) }
+	folded, err := BuildFromSorted(3, []string{"appa", "apple", "apply"}, []int{0, 1, 2}, fold)
+	assert.NoError(t, err)
+	it = PrefixScan(folded, "app")
+	assert.True(t, it.Seek("Apple"), "a seek above the prefix under the comparator should not be clamped")
+	assert.Equal(t, []string{"apple", "apply"}, iteratorKeys(it), "the lower bound should follow the comparator")
+}
+
+func TestIteratorSurvivesNoOpCalls(t *testing.T) {
+	tr := exampleTree()
+	noOps := map[string]func(tr *Tree[int, string]){
+		"GetOrPut hit":           func(tr *Tree[int, string]) { tr.GetOrPut(5, func() string { return "x" }) },
+		"PutIfAbsent hit":        func(tr *Tree[int, string]) { tr.PutIfAbsent(3, "x") },
+		"CompareAndSwap miss":    func(tr *Tree[int, string]) { tr.CompareAndSwap(42, "", "x", eqString) },
+		"CompareAndSwap differs": func(tr *Tree[int, string]) { tr.CompareAndSwap(4, "x", "y", eqString) },
+		"Remove absent":          func(tr *Tree[int, string]) { tr.Remove(42) },
+		"ExtractMatching none":   func(tr *Tree[int, string]) { tr.ExtractMatching(func(int, string) bool { return false }) },
+		"MergeSorted empty":      func(tr *Tree[int, string]) { tr.MergeSorted(nil) },
+		"DeleteRange empty":      func(tr *Tree[int, string]) { tr.DeleteRange(20, 30) },
+	}
+
+	it := tr.Iterator()
+	assert.True(t, it.Next())
+	snap := tr.Snapshot()
+	for name, call := range noOps {
+		assert.NotPanics(t, func() { call(tr) }, name)
+		assert.NotPanics(t, func() { call(snap) }, "%s on a snapshot", name)
+	}
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9}, iteratorKeys(it), "the iterator should keep working")
+
+	empty := New[int, string](3)
+	it = empty.Iterator()
+	empty.PopMin()
+	empty.PopMax()
+	assert.NotPanics(t, func() { it.Next() }, "popping an empty tree is a no-op")
+}
+
+func TestWritesAfterAutoCompact(t *testing.T) {
+	build := func() *Tree[int, string] {
+		tr := New[int, string](4)
+		for key := 0; key < 200; key++ {
+			tr.Put(key, "v")
+		}
+		tr.SetAutoCompact(10)
+		tr.DeleteRange(0, 9)
+		return tr
+	}
+
+	writes := map[string]func(tr *Tree[int, string]){
+		"PutIfAbsent": func(tr *Tree[int, string]) { tr.PutIfAbsent(5, "x") },
+		"GetOrPut":    func(tr *Tree[int, string]) { tr.GetOrPut(5, func() string { return "x" }) },
+	}
+	for name, write := range writes {
+		tr := build()
+		write(tr)
+		assert.Equal(t, 0, tr.deletes, "%s should have compacted first", name)
+		v, _ := tr.Get(5)
+		assert.Equal(t, "x", v, name)
+		assert.NoError(t, tr.Validate(), name)
+	}
+
+	tr := build()
+	assert.True(t, tr.CompareAndSwap(50, "v", "x", eqString))
+	v, _ := tr.Get(50)
+	assert.Equal(t, "x", v, "a swap that compacts first should still land")
+	tr = build()
+	assert.True(t, tr.Remove(100), "a remove that compacts first should still land")
+	assert.False(t, tr.Contains(100))
+	assert.NoError(t, tr.Validate())
+}
+
+func eqString(a, b string) bool { return a == b }
@@ -0,0 +1,398 @@
+package ntree
+
+import (
+	"math"
+	"sort"
+)
+
+// NextAfterNode returns the smallest key in the tree that is greater than
+// the largest key held by n, along with its value. found is false when n
+// is empty or holds the maximum key of the tree.
+func (t *Tree[K, V]) NextAfterNode(n *Node[K, V]) (key K, value V, found bool) {
+	if n == nil || len(n.Elements) == 0 {
+		return key, value, false
+	}
+
+	e := t.successor(n.Elements[len(n.Elements)-1].Key)
+	if e == nil {
+		return key, value, false
+	}
+
+	return e.Key, e.Value, true
+}
+
+// AreAdjacent reports whether a and b are both present and no key lies
+// strictly between them. The order of the arguments does not matter, and
+// a key is not adjacent to itself. bothPresent reports whether a and b
+// are both stored in the tree.
+func (t *Tree[K, V]) AreAdjacent(a, b K) (adjacent, bothPresent bool) {
+	if !t.Contains(a) || !t.Contains(b) {
+		return false, false
+	}
+
+	comp := t.Comparator(a, b)
+	if comp == 0 {
+		return false, true
+	}
+
+	if comp > 0 {
+		a, b = b, a
+	}
+
+	next := t.successor(a)
+	return next != nil && t.Comparator(next.Key, b) == 0, true
+}
+
+// FindFirst returns the smallest key for which pred is true, and its value.
+// pred must be monotonic over the sorted keys: false for every key below
+// some boundary and true from there on. Each node on a single descent is
+// binary searched for the boundary, so pred is called O(log n) times.
+func (t *Tree[K, V]) FindFirst(pred func(K) bool) (key K, value V, found bool) {
+	var first *Element[K, V]
+	for n := t.Root; n != nil; {
+		i := sort.Search(len(n.Elements), func(i int) bool { return pred(n.Elements[i].Key) })
+		if i < len(n.Elements) {
+			first = n.Elements[i]
+		}
+
+		if t.isLeaf(n) {
+			break
+		}
+
+		n = n.Children[i]
+	}
+
+	if first == nil {
+		return key, value, false
+	}
+
+	return first.Key, first.Value, true
+}
+
+// Min returns the smallest key and its value by descending the leftmost
+// child chain. found is false for an empty tree.
+func (t *Tree[K, V]) Min() (key K, value V, found bool) {
+	e := t.minElement()
+	if e == nil {
+		return key, value, false
+	}
+
+	return e.Key, e.Value, true
+}
+
+// Max returns the largest key and its value by descending the rightmost
+// child chain. found is false for an empty tree.
+func (t *Tree[K, V]) Max() (key K, value V, found bool) {
+	e := t.maxElement()
+	if e == nil {
+		return key, value, false
+	}
+
+	return e.Key, e.Value, true
+}
+
+// First is an alias of Min that returns the smallest element as an Entry
+func (t *Tree[K, V]) First() (Entry[K, V], bool) {
+	key, value, found := t.Min()
+	return Entry[K, V]{Key: key, Value: value}, found
+}
+
+// Last is an alias of Max that returns the largest element as an Entry
+func (t *Tree[K, V]) Last() (Entry[K, V], bool) {
+	key, value, found := t.Max()
+	return Entry[K, V]{Key: key, Value: value}, found
+}
+
+// Span returns the minimum and maximum keys of the tree. ok is false when
+// the tree is empty.
+func (t *Tree[K, V]) Span() (min, max K, ok bool) {
+	lo, hi := t.minElement(), t.maxElement()
+	if lo == nil {
+		return min, max, false
+	}
+
+	return lo.Key, hi.Key, true
+}
+
+// ElementAtFraction returns the element at rank round(f*(Size-1)), so 0
+// selects the minimum and 1 the maximum. found is false for an empty tree
+// or when f is outside [0, 1].
+func (t *Tree[K, V]) ElementAtFraction(f float64) (key K, value V, found bool) {
+	if t.Empty() || !(f >= 0 && f <= 1) {
+		return key, value, false
+	}
+
+	e := t.selectAt(int(math.Round(f * float64(t.size-1))))
+	return e.Key, e.Value, true
+}
+
+// StratifiedSample returns n keys spread evenly by position, at ranks
+// i*(Size-1)/(n-1), so the minimum and maximum are always included. n is
+// capped at Size, and a single sample is the minimum.
+func (t *Tree[K, V]) StratifiedSample(n int) []K {
+	n = min(n, t.size)
+	if n < 1 {
+		return nil
+	}
+
+	if n == 1 {
+		return []K{t.minElement().Key}
+	}
+
+	keys := make([]K, n)
+	for i := range keys {
+		keys[i] = t.selectAt(i * (t.size - 1) / (n - 1)).Key
+	}
+
+	return keys
+}
+
+// NodeStartRank returns the 0-based rank, in ascending key order, of the
+// smallest element held by n. ok is false when n is empty or not part of
+// the tree.
+func (t *Tree[K, V]) NodeStartRank(n *Node[K, V]) (rank int, ok bool) {
+	if n == nil || len(n.Elements) == 0 || !t.owns(n) {
+		return 0, false
+	}
+
+	return t.Rank(n.Elements[0].Key), true
+}
+
+// owns reports whether n is reachable from the root by following the
+// parent pointers up and the child slices back down.
+func (t *Tree[K, V]) owns(n *Node[K, V]) bool {
+	for ; n.Parent != nil; n = n.Parent {
+		if t.childIndex(n.Parent, n) < 0 {
+			return false
+		}
+	}
+
+	return n == t.Root
+}
+
+// Rank returns the number of keys strictly less than key, whether or not
+// key itself is in the tree. Whole subtrees to the left of the descent are
+// counted through their cached sizes, so it runs in O(m log n).
+func (t *Tree[K, V]) Rank(key K) int {
+	rank := 0
+	for n := t.Root; n != nil; {
+		ipos, found := t.search(n, key)
+		rank += ipos
+		if t.isLeaf(n) {
+			return rank
+		}
+
+		for _, c := range n.Children[:ipos] {
+			rank += c.subtreeSize
+		}
+
+		if found {
+			return rank + n.Children[ipos].subtreeSize
+		}
+
+		n = n.Children[ipos]
+	}
+
+	return rank
+}
+
+// Select returns the element with the k-th smallest key, counting from 0,
+// or false if k is out of range
+func (t *Tree[K, V]) Select(k int) (K, V, bool) {
+	return unpack(t.selectAt(k))
+}
+
+// CountRange returns the number of keys k with lo <= k <= hi. Neither bound
+// needs to be in the tree, and it is 0 when lo > hi.
+func (t *Tree[K, V]) CountRange(lo, hi K) int {
+	if t.Comparator(lo, hi) > 0 {
+		return 0
+	}
+
+	count := t.Rank(hi) - t.Rank(lo)
+	if t.Contains(hi) {
+		count++
+	}
+
+	return count
+}
+
+// selectAt returns the element with the given 0-based rank in ascending
+// key order, or nil if the rank is out of range. The cached subtree sizes
+// steer a single descent.
+func (t *Tree[K, V]) selectAt(rank int) *Element[K, V] {
+	if rank < 0 || rank >= t.size {
+		return nil
+	}
+
+	n := t.Root
+	for !t.isLeaf(n) {
+		i := 0
+		for ; rank >= n.Children[i].subtreeSize; i++ {
+			rank -= n.Children[i].subtreeSize
+			if rank == 0 {
+				return n.Elements[i]
+			}
+			rank--
+		}
+
+		n = n.Children[i]
+	}
+
+	return n.Elements[rank]
+}
+
+// Bracket returns in a single descent both the floor of key, the largest
+// key <= key, and its ceiling, the smallest key >= key. When key is
+// present both equal it.
+func (t *Tree[K, V]) Bracket(key K) (floorK K, floorV V, hasFloor bool, ceilK K, ceilV V, hasCeil bool) {
+	var floor, ceil *Element[K, V]
+	for n := t.Root; n != nil; {
+		ipos, found := t.search(n, key)
+		if found {
+			floor, ceil = n.Elements[ipos], n.Elements[ipos]
+			break
+		}
+
+		if ipos > 0 {
+			floor = n.Elements[ipos-1]
+		}
+		if ipos < len(n.Elements) {
+			ceil = n.Elements[ipos]
+		}
+
+		if t.isLeaf(n) {
+			break
+		}
+
+		n = n.Children[ipos]
+	}
+
+	if floor != nil {
+		floorK, floorV, hasFloor = floor.Key, floor.Value, true
+	}
+	if ceil != nil {
+		ceilK, ceilV, hasCeil = ceil.Key, ceil.Value, true
+	}
+
+	return floorK, floorV, hasFloor, ceilK, ceilV, hasCeil
+}
+
+// minElement descends the leftmost child chain and returns the element
+// with the smallest key, or nil for an empty tree.
+func (t *Tree[K, V]) minElement() *Element[K, V] {
+	n := t.Root
+	if n == nil || len(n.Elements) == 0 {
+		return nil
+	}
+
+	for !t.isLeaf(n) {
+		n = n.Children[0]
+	}
+
+	return n.Elements[0]
+}
+
+// maxElement descends the rightmost child chain and returns the element
+// with the largest key, or nil for an empty tree.
+func (t *Tree[K, V]) maxElement() *Element[K, V] {
+	n := t.Root
+	if n == nil || len(n.Elements) == 0 {
+		return nil
+	}
+
+	for !t.isLeaf(n) {
+		n = n.Children[len(n.Children)-1]
+	}
+
+	return n.Elements[len(n.Elements)-1]
+}
+
+// Neighbors returns copies of the element stored under key and of its
+// predecessor and successor in key order. prev and next are nil at the
+// boundaries. When key is absent, cur is nil, found is false, and prev and
+// next are the elements on either side of where key would be.
+func (t *Tree[K, V]) Neighbors(key K) (prev, cur, next *Element[K, V], found bool) {
+	if n, index, ok := t.searchRecursive(t.Root, key); ok {
+		cur, found = copyElement(n.Elements[index]), true
+	}
+
+	return copyElement(t.predecessor(key)), cur, copyElement(t.successor(key)), found
+}
+
+// Predecessor returns the element with the largest key strictly less than
+// key, which need not be in the tree, or false if there is none
+func (t *Tree[K, V]) Predecessor(key K) (K, V, bool) {
+	return unpack(t.predecessor(key))
+}
+
+// Successor returns the element with the smallest key strictly greater
+// than key, which need not be in the tree, or false if there is none
+func (t *Tree[K, V]) Successor(key K) (K, V, bool) {
+	return unpack(t.successor(key))
+}
+
+// unpack splits e into its key and value, with false if e is nil.
+func unpack[K comparable, V any](e *Element[K, V]) (key K, value V, ok bool) {
+	if e == nil {
+		return key, value, false
+	}
+
+	return e.Key, e.Value, true
+}
+
+// copyElement returns a copy of e so callers cannot rewrite keys in place,
+// or nil if e is nil.
+func copyElement[K comparable, V any](e *Element[K, V]) *Element[K, V] {
+	if e == nil {
+		return nil
+	}
+
+	c := *e
+	return &c
+}
+
+// predecessor returns the element with the largest key strictly less than
+// key, or nil if there is none.
+func (t *Tree[K, V]) predecessor(key K) *Element[K, V] {
+	var pred *Element[K, V]
+	for n := t.Root; n != nil; {
+		ipos, _ := t.search(n, key)
+		if ipos > 0 {
+			pred = n.Elements[ipos-1]
+		}
+
+		if t.isLeaf(n) {
+			break
+		}
+
+		n = n.Children[ipos]
+	}
+
+	return pred
+}
+
+// successor returns the element with the smallest key strictly greater
+// than key, or nil if there is none. It needs a single root-to-leaf
+// descent, remembering the closest greater separator seen on the way.
+func (t *Tree[K, V]) successor(key K) *Element[K, V] {
+	var succ *Element[K, V]
+	for n := t.Root; n != nil; {
+		ipos, found := t.search(n, key)
+		if found {
+			ipos++
+		}
+
+		if ipos < len(n.Elements) {
+			succ = n.Elements[ipos]
+		}
+
+		if t.isLeaf(n) {
+			break
+		}
+
+		n = n.Children[ipos]
+	}
+
+	return succ
+}
@@ -0,0 +1,315 @@
+package ntree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextAfterNode(t *testing.T) {
+	tr := exampleTree()
+
+	n, found := tr.GetNode(4)
+	assert.True(t, found, "key 4 should be found")
+
+	key, value, found := tr.NextAfterNode(n)
+	assert.True(t, found, "a key should follow the leaf")
+	assert.Equal(t, 6, key, "key after leaf [4 5] should be 6")
+	assert.Equal(t, "f", value, "value should be f")
+
+	n, _ = tr.GetNode(9)
+	_, _, found = tr.NextAfterNode(n)
+	assert.False(t, found, "nothing follows the node holding the maximum")
+}
+
+func TestAreAdjacent(t *testing.T) {
+	tr := exampleTree()
+
+	adjacent, present := tr.AreAdjacent(3, 4)
+	assert.True(t, present, "keys 3 and 4 should be present")
+	assert.True(t, adjacent, "keys 3 and 4 should be adjacent")
+
+	adjacent, _ = tr.AreAdjacent(4, 3)
+	assert.True(t, adjacent, "argument order should not matter")
+
+	adjacent, present = tr.AreAdjacent(3, 5)
+	assert.True(t, present, "keys 3 and 5 should be present")
+	assert.False(t, adjacent, "key 4 lies between 3 and 5")
+
+	adjacent, present = tr.AreAdjacent(9, 10)
+	assert.False(t, present, "key 10 is absent")
+	assert.False(t, adjacent, "absent keys are never adjacent")
+}
+
+func TestSpan(t *testing.T) {
+	tr := exampleTree()
+
+	min, max, ok := tr.Span()
+	assert.True(t, ok, "span should exist for a non-empty tree")
+	assert.Equal(t, 1, min, "min should be 1")
+	assert.Equal(t, 9, max, "max should be 9")
+
+	_, _, ok = New[int, string](3).Span()
+	assert.False(t, ok, "empty tree has no span")
+}
+
+func TestElementAtFraction(t *testing.T) {
+	tr := exampleTree()
+
+	key, value, found := tr.ElementAtFraction(0.0)
+	assert.True(t, found, "fraction 0 should be found")
+	assert.Equal(t, 1, key, "fraction 0 should be the min")
+	assert.Equal(t, "a", value, "value should be a")
+
+	key, _, _ = tr.ElementAtFraction(1.0)
+	assert.Equal(t, 9, key, "fraction 1 should be the max")
+
+	key, _, _ = tr.ElementAtFraction(0.5)
+	assert.Equal(t, 5, key, "fraction 0.5 should be the median")
+
+	_, _, found = tr.ElementAtFraction(1.5)
+	assert.False(t, found, "out-of-range fraction should not be found")
+
+	_, _, found = New[int, string](3).ElementAtFraction(0.5)
+	assert.False(t, found, "empty tree has no elements")
+}
+
+func TestBracket(t *testing.T) {
+	tr := exampleTree()
+	tr.ExtractMatching(func(key int, _ string) bool { return key == 5 })
+
+	floorK, floorV, hasFloor, ceilK, ceilV, hasCeil := tr.Bracket(5)
+	assert.True(t, hasFloor, "5 should have a floor")
+	assert.Equal(t, 4, floorK, "floor of 5 should be 4")
+	assert.Equal(t, "d", floorV, "floor value should be d")
+	assert.True(t, hasCeil, "5 should have a ceiling")
+	assert.Equal(t, 6, ceilK, "ceiling of 5 should be 6")
+	assert.Equal(t, "f", ceilV, "ceiling value should be f")
+
+	floorK, _, _, ceilK, _, _ = tr.Bracket(3)
+	assert.Equal(t, 3, floorK, "present key is its own floor")
+	assert.Equal(t, 3, ceilK, "present key is its own ceiling")
+
+	_, _, hasFloor, ceilK, _, _ = tr.Bracket(0)
+	assert.False(t, hasFloor, "nothing is below the minimum")
+	assert.Equal(t, 1, ceilK, "ceiling of 0 should be 1")
+
+	floorK, _, _, _, _, hasCeil = tr.Bracket(10)
+	assert.Equal(t, 9, floorK, "floor of 10 should be 9")
+	assert.False(t, hasCeil, "nothing is above the maximum")
+}
+
+func TestStratifiedSample(t *testing.T) {
+	tr := exampleTree()
+
+	assert.Equal(t, []int{1, 5, 9}, tr.StratifiedSample(3), "samples should be evenly spaced by rank")
+	assert.Equal(t, []int{1}, tr.StratifiedSample(1), "a single sample is the minimum")
+	assert.Len(t, tr.StratifiedSample(20), tr.Size(), "sample count is capped at Size")
+	assert.Nil(t, New[int, string](3).StratifiedSample(3), "empty tree has no samples")
+}
+
+func TestNodeStartRank(t *testing.T) {
+	tr := exampleTree()
+
+	n, _ := tr.GetNode(5)
+	rank, ok := tr.NodeStartRank(n)
+	assert.True(t, ok, "leaf [4 5] is part of the tree")
+	assert.Equal(t, tr.Rank(n.Elements[0].Key), rank, "start rank should match the rank of its first key")
+	assert.Equal(t, 3, rank, "key 4 has three smaller keys")
+
+	rank, _ = tr.NodeStartRank(tr.Root)
+	assert.Equal(t, 2, rank, "root starts at key 3")
+
+	_, ok = tr.NodeStartRank(&Node[int, string]{Elements: []*Element[int, string]{{Key: 4}}})
+	assert.False(t, ok, "a detached node is not part of the tree")
+}
+
+func TestNeighbors(t *testing.T) {
+	tr := exampleTree()
+
+	prev, cur, next, found := tr.Neighbors(5)
+	assert.True(t, found, "key 5 should be found")
+	assert.Equal(t, 4, prev.Key, "predecessor of 5 should be 4")
+	assert.Equal(t, 5, cur.Key, "current should be 5")
+	assert.Equal(t, "e", cur.Value, "current value should be e")
+	assert.Equal(t, 6, next.Key, "successor of 5 should be 6")
+
+	prev, cur, next, _ = tr.Neighbors(3)
+	assert.Equal(t, 2, prev.Key, "predecessor of internal key 3 should be 2")
+	assert.Equal(t, 4, next.Key, "successor of internal key 3 should be 4")
+
+	prev, _, next, _ = tr.Neighbors(1)
+	assert.Nil(t, prev, "the min has no predecessor")
+	assert.Equal(t, 2, next.Key, "successor of 1 should be 2")
+
+	prev, _, next, _ = tr.Neighbors(9)
+	assert.Equal(t, 8, prev.Key, "predecessor of 9 should be 8")
+	assert.Nil(t, next, "the max has no successor")
+
+	cur.Key = 100
+	_, found = tr.Get(3)
+	assert.True(t, found, "returned elements should be copies")
+
+	tr.Remove(5)
+	prev, cur, next, found = tr.Neighbors(5)
+	assert.False(t, found, "key 5 was removed")
+	assert.Nil(t, cur, "absent key has no current element")
+	assert.Equal(t, 4, prev.Key, "elements around the gap are still reported")
+	assert.Equal(t, 6, next.Key, "elements around the gap are still reported")
+}
+
+func TestMinMax(t *testing.T) {
+	leaf := New[int, string](5)
+	leaf.Put(2, "b")
+	leaf.Put(1, "a")
+	leaf.Put(3, "c")
+
+	key, value, found := leaf.Min()
+	assert.True(t, found, "leaf root should have a min")
+	assert.Equal(t, 1, key, "min of leaf root should be 1")
+	assert.Equal(t, "a", value, "min value should be a")
+	key, _, _ = leaf.Max()
+	assert.Equal(t, 3, key, "max of leaf root should be 3")
+
+	deep := New[int, int](3)
+	for k := 100; k > 0; k-- {
+		deep.Put(k, -k)
+	}
+	assert.Greater(t, deep.Height(), 3, "tree should be deep")
+	key2, value2, _ := deep.Min()
+	assert.Equal(t, 1, key2, "min of deep tree should be 1")
+	assert.Equal(t, -1, value2, "min value should be -1")
+	key2, _, _ = deep.Max()
+	assert.Equal(t, 100, key2, "max of deep tree should be 100")
+
+	allocs := testing.AllocsPerRun(10, func() { deep.Min(); deep.Max() })
+	assert.Zero(t, allocs, "Min and Max should not allocate")
+
+	_, _, found = New[int, string](3).Min()
+	assert.False(t, found, "empty tree has no min")
+	_, _, found = New[int, string](3).Max()
+	assert.False(t, found, "empty tree has no max")
+}
+
+func TestFindFirst(t *testing.T) {
+	tr := exampleTree()
+
+	key, value, found := tr.FindFirst(func(k int) bool { return k >= 5 })
+	assert.True(t, found, "a key >= 5 exists")
+	assert.Equal(t, 5, key, "first key >= 5 should be 5")
+	assert.Equal(t, "e", value, "value should be e")
+
+	key, _, _ = tr.FindFirst(func(k int) bool { return k > 3 })
+	assert.Equal(t, 4, key, "first key after a separator is found in its right subtree")
+
+	key, _, _ = tr.FindFirst(func(int) bool { return true })
+	assert.Equal(t, 1, key, "an always-true predicate finds the min")
+
+	_, _, found = tr.FindFirst(func(k int) bool { return k > 9 })
+	assert.False(t, found, "nothing satisfies the predicate")
+
+	big := New[int, int](4)
+	for k := 0; k < 1000; k++ {
+		big.Put(k, k)
+	}
+	calls := 0
+	key, _, _ = big.FindFirst(func(k int) bool { calls++; return k >= 777 })
+	assert.Equal(t, 777, key, "boundary should be found in a deep tree")
+	assert.Less(t, calls, 40, "the predicate should be called O(log n) times")
+}
+
+func TestSelectRank(t *testing.T) {
+	tr := New[int, int](4)
+	for _, key := range rand.New(rand.NewSource(1)).Perm(300) {
+		tr.Put(key*2, key)
+	}
+	for key := 0; key < 600; key += 6 {
+		tr.Remove(key)
+	}
+	keys := tr.Keys()
+
+	for k, want := range keys {
+		key, value, ok := tr.Select(k)
+		assert.True(t, ok)
+		assert.Equal(t, want, key, "Select(%d) should return the k-th smallest key", k)
+		assert.Equal(t, want/2, value)
+		assert.Equal(t, k, tr.Rank(want), "Rank of a present key should be its index")
+		assert.Equal(t, k+1, tr.Rank(want+1), "Rank of an absent key should count the keys below it")
+	}
+
+	assert.Equal(t, 0, tr.Rank(-5), "nothing is below the minimum")
+	assert.Equal(t, len(keys), tr.Rank(1000), "everything is below a key past the maximum")
+
+	_, _, ok := tr.Select(-1)
+	assert.False(t, ok, "negative k is out of range")
+	_, _, ok = tr.Select(len(keys))
+	assert.False(t, ok, "k == Size is out of range")
+}
+
+func TestCountRange(t *testing.T) {
+	tr := New[int, int](3)
+	for key := 10; key <= 100; key += 10 {
+		tr.Put(key, key)
+	}
+
+	assert.Equal(t, 3, tr.CountRange(20, 40), "both bounds present")
+	assert.Equal(t, 3, tr.CountRange(15, 45), "both bounds absent")
+	assert.Equal(t, 2, tr.CountRange(20, 39), "only lo present")
+	assert.Equal(t, 2, tr.CountRange(21, 40), "only hi present")
+	assert.Equal(t, 1, tr.CountRange(50, 50), "a single present key")
+	assert.Equal(t, 0, tr.CountRange(51, 59), "an empty gap")
+	assert.Equal(t, 10, tr.CountRange(0, 1000), "a range covering everything")
+	assert.Equal(t, 0, tr.CountRange(40, 20), "lo > hi")
+	assert.Equal(t, 0, New[int, int](3).CountRange(0, 10), "an empty tree")
+}
+
+func TestPredecessorSuccessor(t *testing.T) {
+	tr := New[int, string](3)
+	for key := 10; key <= 100; key += 10 {
+		tr.Put(key, fmt.Sprint(key))
+	}
+
+	for key := 10; key <= 100; key += 5 {
+		if key > 10 {
+			prev, value, ok := tr.Predecessor(key)
+			assert.True(t, ok, "%d should have a predecessor", key)
+			assert.Equal(t, (key-1)/10*10, prev, "predecessor of %d", key)
+			assert.Equal(t, fmt.Sprint(prev), value)
+		}
+		if key < 100 {
+			next, value, ok := tr.Successor(key)
+			assert.True(t, ok, "%d should have a successor", key)
+			assert.Equal(t, key/10*10+10, next, "successor of %d", key)
+			assert.Equal(t, fmt.Sprint(next), value)
+		}
+	}
+
+	_, _, ok := tr.Predecessor(10)
+	assert.False(t, ok, "the min has no predecessor")
+	_, _, ok = tr.Successor(100)
+	assert.False(t, ok, "the max has no successor")
+	prev, _, ok := tr.Predecessor(1000)
+	assert.True(t, ok)
+	assert.Equal(t, 100, prev, "a key past the max has the max as predecessor")
+	_, _, ok = New[int, string](3).Successor(0)
+	assert.False(t, ok, "an empty tree has no successor")
+}
+
+func TestFirstLast(t *testing.T) {
+	for _, tr := range []*Tree[int, string]{exampleTree(), New[int, string](3)} {
+		minKey, minValue, minFound := tr.Min()
+		first, found := tr.First()
+		assert.Equal(t, minFound, found, "First should agree with Min")
+		assert.Equal(t, Entry[int, string]{Key: minKey, Value: minValue}, first)
+
+		maxKey, maxValue, maxFound := tr.Max()
+		last, found := tr.Last()
+		assert.Equal(t, maxFound, found, "Last should agree with Max")
+		assert.Equal(t, Entry[int, string]{Key: maxKey, Value: maxValue}, last)
+	}
+
+	first, _ := exampleTree().First()
+	assert.Equal(t, Entry[int, string]{Key: 1, Value: "a"}, first)
+}
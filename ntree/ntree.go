@@ -2,8 +2,10 @@ package ntree
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 )
 
@@ -14,12 +16,24 @@ type Tree[K comparable, V any] struct {
 	Comparator func(x, y K) int
 	size       int // total number of keys in the tree
 	m          int // maximum number of keys in a node
+
+	deletes     int // removals since the tree was last compacted
+	autoCompact int // compact after this many removals, 0 disables
+	overwrites  int // puts that replaced the value of an existing key
+	mods        int // mutations so far, checked by iterators
+	readOnly    bool
 }
 
 type Node[K comparable, V any] struct {
 	Parent   *Node[K, V]
 	Children []*Node[K, V]
 	Elements []*Element[K, V]
+
+	// subtreeSize is the number of elements in the subtree rooted at this
+	// node. Inserts and removes adjust it along the path to the root, and
+	// splits, merges, borrows and bulk loads recount the nodes they rebuild,
+	// so Select and Rank can skip whole subtrees.
+	subtreeSize int
 }
 
 type Element[K comparable, V any] struct {
@@ -27,16 +41,30 @@ type Element[K comparable, V any] struct {
 	Value V
 }
 
-// New returns a new n-ary tree
+// Entry is a key-value pair returned by value. Unlike an *Element, it is
+// detached from the tree and can be passed around freely.
+type Entry[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// New returns a new n-ary tree. m is the order of the tree, the maximum
+// number of children per node; it panics if m is less than 3, since smaller
+// nodes cannot be split.
 func New[K cmp.Ordered, V any](m int) *Tree[K, V] {
+	if m < 3 {
+		panic(fmt.Errorf("ntree: order m must be >= 3, got %d", m))
+	}
+
 	return &Tree[K, V]{Comparator: cmp.Compare[K], m: m}
 }
 
 // Put inserts or updates a key-value pair into the tree
 func (t *Tree[K, V]) Put(key K, value V) {
+	t.beforeWrite()
 	ele := &Element[K, V]{Key: key, Value: value}
 	if t.Root == nil {
-		t.Root = &Node[K, V]{Elements: []*Element[K, V]{ele}}
+		t.Root = &Node[K, V]{Elements: []*Element[K, V]{ele}, subtreeSize: 1}
 		t.size++
 		return
 	}
@@ -46,6 +74,178 @@ func (t *Tree[K, V]) Put(key K, value V) {
 	}
 }
 
+// MergeSorted inserts a batch of elements sorted in ascending key order,
+// overwriting keys already in the tree. Keys that fall into the leaf
+// reached by the previous insert are placed there directly instead of
+// descending from the root again. Out-of-order input is still inserted
+// correctly, just without the shortcut.
+func (t *Tree[K, V]) MergeSorted(elements []Element[K, V]) {
+	if len(elements) == 0 {
+		return
+	}
+
+	t.beforeWrite()
+
+	var leaf *Node[K, V]
+	var upper *Element[K, V]
+	for i, e := range elements {
+		ele := &Element[K, V]{Key: e.Key, Value: e.Value}
+
+		// re-descend when the input goes backwards, the key leaves the
+		// leaf's range, or the insert would split the leaf and detach it
+		if leaf == nil || len(leaf.Elements) >= t.maxElements() ||
+			(i > 0 && t.Comparator(ele.Key, elements[i-1].Key) < 0) ||
+			(upper != nil && t.Comparator(ele.Key, upper.Key) >= 0) {
+			leaf, upper = t.leafFor(ele.Key)
+		}
+
+		if leaf == nil {
+			t.Put(ele.Key, ele.Value)
+			continue
+		}
+
+		if t.insertIntoLeaf(leaf, ele) {
+			t.size++
+		}
+	}
+}
+
+// PutAll inserts every key with the value at the same index, overwriting
+// keys already in the tree. The pairs are sorted first so consecutive
+// inserts land in the same leaf and go through MergeSorted; when a key
+// repeats, the later pair wins as with a loop of Put. It panics if keys
+// and values differ in length.
+func (t *Tree[K, V]) PutAll(keys []K, values []V) {
+	if len(keys) != len(values) {
+		panic(fmt.Errorf("ntree: PutAll got %d keys but %d values", len(keys), len(values)))
+	}
+
+	// sort indexes rather than pairs, breaking ties by position so the
+	// later of two equal keys ends up last
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		if c := t.Comparator(keys[a], keys[b]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	elements := make([]Element[K, V], len(order))
+	for i, j := range order {
+		elements[i] = Element[K, V]{Key: keys[j], Value: values[j]}
+	}
+
+	t.MergeSorted(elements)
+}
+
+// Merge inserts every element of other into t. For a key present in both,
+// the stored value becomes onConflict(t's value, other's value), or other's
+// value if onConflict is nil. The two trees may have different orders;
+// other is not modified.
+func (t *Tree[K, V]) Merge(other *Tree[K, V], onConflict func(a, b V) V) {
+	other.walk(other.Root, func(e *Element[K, V]) bool {
+		t.Update(e.Key, func(old V, found bool) V {
+			if found && onConflict != nil {
+				return onConflict(old, e.Value)
+			}
+			return e.Value
+		})
+		return true
+	})
+}
+
+// leafFor descends to the leaf where key belongs and returns it along with
+// the nearest separator above it, nil if there is none. The leaf is nil
+// when the tree is empty or key is held by an internal node.
+func (t *Tree[K, V]) leafFor(key K) (leaf *Node[K, V], upper *Element[K, V]) {
+	for n := t.Root; n != nil; {
+		ipos, found := t.search(n, key)
+		if found && !t.isLeaf(n) {
+			return nil, nil
+		}
+
+		if t.isLeaf(n) {
+			return n, upper
+		}
+
+		if ipos < len(n.Elements) {
+			upper = n.Elements[ipos]
+		}
+
+		n = n.Children[ipos]
+	}
+
+	return nil, nil
+}
+
+// PutIf stores the key-value pair only when cond, given the current value
+// and whether the key exists, returns true. It reports whether the write
+// happened.
+func (t *Tree[K, V]) PutIf(key K, value V, cond func(existing V, exists bool) bool) bool {
+	existing, exists := t.Get(key)
+	if !cond(existing, exists) {
+		return false
+	}
+
+	t.Put(key, value)
+	return true
+}
+
+// PutIfAbsent stores the key-value pair only if the key is not in the tree.
+// It returns the value now stored under key, which is the existing one if
+// the key was present, and whether the pair was inserted.
+func (t *Tree[K, V]) PutIfAbsent(key K, value V) (actual V, inserted bool) {
+	e, leaf := t.find(key)
+	if e != nil {
+		return e.Value, false
+	}
+
+	if t.beforeWrite() {
+		_, leaf = t.find(key)
+	}
+	t.insertAt(leaf, &Element[K, V]{Key: key, Value: value})
+	return value, true
+}
+
+// GetOrPut returns the value stored under key if there is one. Otherwise
+// it calls factory once, stores the result under key and returns it.
+// loaded reports whether the value was already present. factory must not
+// modify the tree.
+func (t *Tree[K, V]) GetOrPut(key K, factory func() V) (value V, loaded bool) {
+	e, leaf := t.find(key)
+	if e != nil {
+		return e.Value, true
+	}
+
+	value = factory()
+	if t.beforeWrite() {
+		_, leaf = t.find(key)
+	}
+	t.insertAt(leaf, &Element[K, V]{Key: key, Value: value})
+	return value, false
+}
+
+// Update stores fn(old, found) under key and returns it, where old is the
+// current value and found reports whether the key was present. An absent
+// key is inserted. The tree is searched once; fn must not modify it.
+func (t *Tree[K, V]) Update(key K, fn func(old V, found bool) V) V {
+	t.beforeWrite()
+	e, leaf := t.find(key)
+	if e != nil {
+		e.Value = fn(e.Value, true)
+		t.overwrites++
+		return e.Value
+	}
+
+	var zero V
+	value := fn(zero, false)
+	t.insertAt(leaf, &Element[K, V]{Key: key, Value: value})
+	return value
+}
+
 // Get retrieves the value associated with the key from the tree
 func (t *Tree[K, V]) Get(key K) (value V, found bool) {
 	if t.Root == nil {
@@ -60,6 +260,144 @@ func (t *Tree[K, V]) Get(key K) (value V, found bool) {
 	return value, false
 }
 
+// GetEntry returns the key and the value stored under it as an Entry, or
+// false if the key is absent
+func (t *Tree[K, V]) GetEntry(key K) (Entry[K, V], bool) {
+	e, _ := t.find(key)
+	if e == nil {
+		return Entry[K, V]{}, false
+	}
+
+	return Entry[K, V]{Key: e.Key, Value: e.Value}, true
+}
+
+// Remove deletes the key and its value from the tree, reporting whether the
+// key was present. Nodes left below minimum fill borrow from or merge with
+// a sibling, and the root collapses when it runs out of elements.
+func (t *Tree[K, V]) Remove(key K) bool {
+	return t.remove(key) != nil
+}
+
+// PopMin removes the element with the smallest key and returns it, or
+// false if the tree is empty
+func (t *Tree[K, V]) PopMin() (key K, value V, ok bool) {
+	if t.Root == nil {
+		return key, value, false
+	}
+
+	t.beforeWrite()
+
+	n := t.Root
+	for !t.isLeaf(n) {
+		n = n.Children[0]
+	}
+
+	ele := n.Elements[0]
+	t.removeFromLeaf(n, 0)
+	return ele.Key, ele.Value, true
+}
+
+// PopMax removes the element with the largest key and returns it, or
+// false if the tree is empty
+func (t *Tree[K, V]) PopMax() (key K, value V, ok bool) {
+	if t.Root == nil {
+		return key, value, false
+	}
+
+	t.beforeWrite()
+
+	n := t.Root
+	for !t.isLeaf(n) {
+		n = n.Children[len(n.Children)-1]
+	}
+
+	ele := n.Elements[len(n.Elements)-1]
+	t.removeFromLeaf(n, len(n.Elements)-1)
+	return ele.Key, ele.Value, true
+}
+
+// ExtractMatching removes every element for which match returns true and
+// returns the removed elements in ascending key order. The tree is
+// rebalanced as elements are removed.
+func (t *Tree[K, V]) ExtractMatching(match func(K, V) bool) []Element[K, V] {
+	var extracted []Element[K, V]
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		if match(e.Key, e.Value) {
+			extracted = append(extracted, *e)
+		}
+		return true
+	})
+
+	for _, e := range extracted {
+		t.remove(e.Key)
+	}
+
+	return extracted
+}
+
+// DeleteRange removes every key k with lo <= k <= hi and returns how many
+// were removed; it does nothing when lo > hi. When the range holds a large
+// share of the tree, the remaining elements are bulk loaded into a fresh
+// tree in one pass instead of rebalancing after each removal.
+func (t *Tree[K, V]) DeleteRange(lo, hi K) int {
+	count := t.CountRange(lo, hi)
+	if count == 0 {
+		return 0
+	}
+
+	t.beforeWrite()
+
+	// removing one key costs a descent and a rebalance along the path, so
+	// past about size/height keys a rebuild is cheaper
+	if count*t.Height() >= t.size {
+		kept := make([]*Element[K, V], 0, t.size-count)
+		t.walk(t.Root, func(e *Element[K, V]) bool {
+			if t.Comparator(e.Key, lo) < 0 || t.Comparator(e.Key, hi) > 0 {
+				kept = append(kept, e)
+			}
+			return true
+		})
+
+		t.load(kept)
+		t.deletes = 0
+		return count
+	}
+
+	keys := make([]K, 0, count)
+	c := newCursor(t)
+	c.seek(lo)
+	for e := c.next(); e != nil && t.Comparator(e.Key, hi) <= 0; e = c.next() {
+		keys = append(keys, e.Key)
+	}
+
+	for _, key := range keys {
+		t.remove(key)
+	}
+
+	return count
+}
+
+// CompareAndSwap replaces the value stored under key with new only if the
+// current value equals old according to eq. It reports whether the swap
+// happened; an absent key is never swapped.
+func (t *Tree[K, V]) CompareAndSwap(key K, old, new V, eq func(a, b V) bool) bool {
+	e, _ := t.find(key)
+	if e == nil || !eq(e.Value, old) {
+		return false
+	}
+
+	// compaction moves elements to new nodes but keeps them, so e stays valid
+	t.beforeWrite()
+	e.Value = new
+	return true
+}
+
+// Contains reports whether the key is present in the tree
+func (t *Tree[K, V]) Contains(key K) bool {
+	_, _, found := t.searchRecursive(t.Root, key)
+	return found
+}
+
 func (t *Tree[K, V]) GetNode(key K) (*Node[K, V], bool) {
 	if t.Root == nil {
 		return nil, false
@@ -69,10 +407,26 @@ func (t *Tree[K, V]) GetNode(key K) (*Node[K, V], bool) {
 	return n, found
 }
 
+// OverwriteCount returns how many puts over the lifetime of the tree hit a
+// key that was already present and replaced its value.
+func (t *Tree[K, V]) OverwriteCount() int {
+	return t.overwrites
+}
+
 func (t *Tree[K, V]) Size() int {
 	return t.size
 }
 
+// SubtreeSize returns the number of elements stored in the subtree rooted
+// at n. The count is cached on the node, so this is O(1).
+func (n *Node[K, V]) SubtreeSize() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.subtreeSize
+}
+
 func (n *Node[K, V]) Size() int {
 	if n == nil {
 		return 0
@@ -86,57 +440,224 @@ func (n *Node[K, V]) Size() int {
 	return s
 }
 
+// Clone returns an independent deep copy of the tree. Every node and
+// element is freshly allocated and the copy has exactly the same shape as
+// the original, with children visited in slice order.
+func (t *Tree[K, V]) Clone() *Tree[K, V] {
+	c := *t
+	c.Root = t.cloneNode(t.Root, nil)
+	c.readOnly = false
+	return &c
+}
+
+// Snapshot returns a read-only deep copy of the tree. Lookups and
+// iteration work as usual, but any method that would modify it panics, so
+// it can serve reads while the original keeps changing. Clone the snapshot
+// to get a writable copy again.
+func (t *Tree[K, V]) Snapshot() *Tree[K, V] {
+	s := t.Clone()
+	s.readOnly = true
+	return s
+}
+
+func (t *Tree[K, V]) cloneNode(n, parent *Node[K, V]) *Node[K, V] {
+	if n == nil {
+		return nil
+	}
+
+	c := &Node[K, V]{
+		Parent:      parent,
+		Elements:    make([]*Element[K, V], len(n.Elements)),
+		subtreeSize: n.subtreeSize,
+	}
+	for i, e := range n.Elements {
+		ele := *e
+		c.Elements[i] = &ele
+	}
+
+	if !t.isLeaf(n) {
+		c.Children = make([]*Node[K, V], len(n.Children))
+		for i, child := range n.Children {
+			c.Children[i] = t.cloneNode(child, c)
+		}
+	}
+
+	return c
+}
+
 func (t *Tree[K, V]) Clear() {
+	t.mutate()
 	t.Root = nil
 	t.size = 0
+	t.deletes = 0
 }
 
-func (t *Tree[K, V]) Empty() bool {
-	return t.size == 0
+// Reset empties the tree like Clear, but first walks every node and drops
+// its Parent, Children and Elements references. Clear only detaches the
+// root and leaves the collector to discover that the whole node graph is
+// unreachable; Reset breaks it up so that for very large trees the memory
+// can be reclaimed promptly, even if a stray reference to one node
+// survives.
+func (t *Tree[K, V]) Reset() {
+	t.mutate()
+	t.resetNode(t.Root)
+	t.Clear()
 }
 
-func (t *Tree[K, V]) Print(w io.Writer) {
-	if t.Root == nil {
+// resetNode clears the references held by n and its descendants,
+// children first.
+func (t *Tree[K, V]) resetNode(n *Node[K, V]) {
+	if n == nil {
 		return
 	}
 
-	t.print(w, t.Root, 0)
+	for _, c := range n.Children {
+		t.resetNode(c)
+	}
+
+	n.Parent, n.Children, n.Elements = nil, nil, nil
+	n.subtreeSize = 0
 }
 
-func (t *Tree[K, V]) print(w io.Writer, n *Node[K, V], level int) {
-	if n == nil {
-		return
+// Compact rebuilds the tree with every node filled as evenly as possible
+// and the fewest nodes the order allows. Deletions leave nodes close to
+// minimum fill, so long-lived trees can shrink considerably.
+func (t *Tree[K, V]) Compact() {
+	t.load(t.elements())
+	t.deletes = 0
+}
+
+// SetAutoCompact makes the tree compact itself on the first mutation after
+// afterDeletes elements have been removed. Zero or a negative value turns
+// automatic compaction off.
+func (t *Tree[K, V]) SetAutoCompact(afterDeletes int) {
+	t.autoCompact = max(afterDeletes, 0)
+}
+
+// errReadOnly is the panic value of a mutation of a snapshot
+var errReadOnly = errors.New("ntree: snapshot is read-only")
+
+// mutate must be called before anything in the tree changes. It panics on
+// a snapshot and counts the mutation, so iterators opened before it fail
+// fast.
+func (t *Tree[K, V]) mutate() {
+	if t.readOnly {
+		panic(errReadOnly)
 	}
+	t.mods++
+}
 
-	for e := 0; e < len(n.Elements)+1; e++ {
-		if e < len(n.Children) {
-			t.print(w, n.Children[e], level+1)
-		}
+// beforeWrite runs the housekeeping due before any mutation of the tree.
+// Callers look up what they need first and call it only once they know
+// they will write, so that no-op calls leave iterators and snapshots
+// alone. It reports whether it compacted the tree, which replaces every
+// node; elements are kept, so only node pointers must be looked up again.
+func (t *Tree[K, V]) beforeWrite() bool {
+	t.mutate()
+	if t.autoCompact > 0 && t.deletes >= t.autoCompact {
+		t.Compact()
+		return true
+	}
 
-		if e < len(n.Elements) {
-			w.Write([]byte(strings.Repeat("  ", level)))
-			//w.Write([]byte(strings.Repeat("  ", level)))
-			fmt.Fprintf(w, "%v\n", n.Elements[e].Value)
+	return false
+}
+
+func (t *Tree[K, V]) Empty() bool {
+	return t.size == 0
+}
+
+// Print writes the String representation of the tree to w
+func (t *Tree[K, V]) Print(w io.Writer) {
+	fmt.Fprintln(w, t.String())
+}
+
+// String returns the keys of the tree one level per line, root first, with
+// the keys of each node in brackets:
+//
+//	[3 6]
+//	[1 2] [4 5] [7 8 9]
+//
+// An empty tree is "<empty>".
+func (t *Tree[K, V]) String() string {
+	levels := t.LevelNodeGroups()
+	if len(levels) == 0 {
+		return "<empty>"
+	}
+
+	lines := make([]string, len(levels))
+	for i, groups := range levels {
+		nodes := make([]string, len(groups))
+		for j, keys := range groups {
+			nodes[j] = fmt.Sprint(keys)
 		}
+		lines[i] = strings.Join(nodes, " ")
 	}
+
+	return strings.Join(lines, "\n")
 }
 
+// Height returns the number of levels in the tree: 0 for an empty tree, 1
+// when the root is a leaf, and one more for every level of children below.
 func (t *Tree[K, V]) Height() int {
 	return t.height(t.Root)
 }
 
+// height counts the nodes on the leftmost path down from n. Every leaf of
+// a B-tree is at the same depth, so any path would give the same count.
 func (t *Tree[K, V]) height(n *Node[K, V]) int {
 	h := 0
-	for ; n != nil; n = n.Children[0] {
+	for n != nil {
 		h++
-		if len(n.Children) == 0 {
+		if t.isLeaf(n) {
 			break
 		}
+
+		n = n.Children[0]
 	}
 
 	return h
 }
 
+// walk visits the elements of the subtree rooted at n in ascending key
+// order, stopping as soon as fn returns false. It reports whether the
+// walk ran to completion.
+func (t *Tree[K, V]) walk(n *Node[K, V], fn func(e *Element[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	for i, e := range n.Elements {
+		if !t.isLeaf(n) && !t.walk(n.Children[i], fn) {
+			return false
+		}
+
+		if !fn(e) {
+			return false
+		}
+	}
+
+	if !t.isLeaf(n) {
+		return t.walk(n.Children[len(n.Elements)], fn)
+	}
+
+	return true
+}
+
+// elements returns the elements of the tree in ascending key order.
+func (t *Tree[K, V]) elements() []*Element[K, V] {
+	elements := make([]*Element[K, V], 0, t.size)
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		elements = append(elements, e)
+		return true
+	})
+
+	return elements
+}
+
+// searchRecursive descends from n to the node holding key. Every internal
+// node has exactly len(Elements)+1 children, so the insertion position
+// returned by search, which is at most len(Elements), is always a valid
+// child index.
 func (t *Tree[K, V]) searchRecursive(n *Node[K, V], key K) (*Node[K, V], int, bool) {
 	if t.Empty() {
 		return nil, 0, false
@@ -164,6 +685,11 @@ func (t *Tree[K, V]) maxElements() int {
 	return t.m - 1
 }
 
+// minElements is the fewest keys a non-root node may hold, ceil(m/2)-1.
+func (t *Tree[K, V]) minElements() int {
+	return (t.m - 1) / 2
+}
+
 func (t *Tree[K, V]) insert(n *Node[K, V], ele *Element[K, V]) bool {
 	if t.isLeaf(n) {
 		return t.insertIntoLeaf(n, ele)
@@ -172,6 +698,36 @@ func (t *Tree[K, V]) insert(n *Node[K, V], ele *Element[K, V]) bool {
 	return t.insertIntoChildren(n, ele)
 }
 
+// find descends from the root and returns the element holding key. When
+// the key is absent it returns nil and the leaf the key belongs in, which
+// is nil only for an empty tree.
+func (t *Tree[K, V]) find(key K) (*Element[K, V], *Node[K, V]) {
+	n := t.Root
+	for n != nil {
+		ipos, found := t.search(n, key)
+		if found {
+			return n.Elements[ipos], nil
+		}
+		if t.isLeaf(n) {
+			return nil, n
+		}
+		n = n.Children[ipos]
+	}
+
+	return nil, nil
+}
+
+// insertAt adds ele, whose key is known to be absent, to the leaf returned
+// by find
+func (t *Tree[K, V]) insertAt(leaf *Node[K, V], ele *Element[K, V]) {
+	if leaf == nil {
+		t.Root = &Node[K, V]{Elements: []*Element[K, V]{ele}, subtreeSize: 1}
+	} else {
+		t.insertIntoLeaf(leaf, ele)
+	}
+	t.size++
+}
+
 func (t *Tree[K, V]) isLeaf(n *Node[K, V]) bool {
 	return len(n.Children) == 0
 }
@@ -182,22 +738,29 @@ func (t *Tree[K, V]) insertIntoLeaf(n *Node[K, V], ele *Element[K, V]) bool {
 	ipos, found := t.search(n, ele.Key)
 	if found {
 		n.Elements[ipos] = ele
+		t.overwrites++
 		return false
 	}
 
 	n.Elements = append(n.Elements, nil)
 	copy(n.Elements[ipos+1:], n.Elements[ipos:])
 	n.Elements[ipos] = ele
+	for p := n; p != nil; p = p.Parent {
+		p.subtreeSize++
+	}
+
 	t.split(n)
 	return true
 }
 
 // insertIntoChildren finds the correct child node to insert the element
-// into and recursively calls insert on that child node.
+// into and recursively calls insert on that child node. As in
+// searchRecursive, ipos is a valid index into n.Children.
 func (t *Tree[K, V]) insertIntoChildren(n *Node[K, V], ele *Element[K, V]) bool {
 	ipos, found := t.search(n, ele.Key)
 	if found {
 		n.Elements[ipos] = ele
+		t.overwrites++
 		return false
 	}
 
@@ -252,8 +815,8 @@ func (t *Tree[K, V]) isRoot(n *Node[K, V]) bool {
 
 func (t *Tree[K, V]) splitRoot() {
 	mid := (t.m - 1) / 2
-	left := &Node[K, V]{Elements: t.Root.Elements[:mid]}
-	right := &Node[K, V]{Elements: t.Root.Elements[mid+1:]}
+	left := &Node[K, V]{Elements: append([]*Element[K, V](nil), t.Root.Elements[:mid]...)}
+	right := &Node[K, V]{Elements: append([]*Element[K, V](nil), t.Root.Elements[mid+1:]...)}
 
 	// what if the root has children?
 	if !t.isLeaf(t.Root) {
@@ -267,9 +830,13 @@ func (t *Tree[K, V]) splitRoot() {
 		}
 	}
 
+	t.recount(left)
+	t.recount(right)
+
 	newRoot := &Node[K, V]{
-		Elements: []*Element[K, V]{t.Root.Elements[mid]},
-		Children: []*Node[K, V]{left, right},
+		Elements:    []*Element[K, V]{t.Root.Elements[mid]},
+		Children:    []*Node[K, V]{left, right},
+		subtreeSize: t.Root.subtreeSize,
 	}
 
 	left.Parent = newRoot
@@ -281,8 +848,8 @@ func (t *Tree[K, V]) splitNonRoot(n *Node[K, V]) {
 	mid := (t.m - 1) / 2
 	parent := n.Parent
 
-	left := &Node[K, V]{Elements: n.Elements[:mid], Parent: parent}
-	right := &Node[K, V]{Elements: n.Elements[mid+1:], Parent: parent}
+	left := &Node[K, V]{Elements: append([]*Element[K, V](nil), n.Elements[:mid]...), Parent: parent}
+	right := &Node[K, V]{Elements: append([]*Element[K, V](nil), n.Elements[mid+1:]...), Parent: parent}
 
 	if !t.isLeaf(n) {
 		left.Children = append([]*Node[K, V](nil), n.Children[:mid+1]...)
@@ -295,6 +862,9 @@ func (t *Tree[K, V]) splitNonRoot(n *Node[K, V]) {
 		}
 	}
 
+	t.recount(left)
+	t.recount(right)
+
 	ipos, _ := t.search(parent, n.Elements[mid].Key)
 	parent.Elements = append(parent.Elements, nil)
 	copy(parent.Elements[ipos+1:], parent.Elements[ipos:])
@@ -308,3 +878,186 @@ func (t *Tree[K, V]) splitNonRoot(n *Node[K, V]) {
 
 	t.split(parent)
 }
+
+// recount recomputes the cached subtree size of n from its own elements
+// and the cached sizes of its children.
+func (t *Tree[K, V]) recount(n *Node[K, V]) {
+	n.subtreeSize = len(n.Elements)
+	for _, c := range n.Children {
+		n.subtreeSize += c.subtreeSize
+	}
+}
+
+// remove deletes the element with the given key and rebalances the tree.
+// It returns the removed element, or nil if the key is absent.
+func (t *Tree[K, V]) remove(key K) *Element[K, V] {
+	n, index, found := t.searchRecursive(t.Root, key)
+	if !found {
+		return nil
+	}
+
+	if t.beforeWrite() {
+		n, index, _ = t.searchRecursive(t.Root, key)
+	}
+
+	ele := n.Elements[index]
+	if !t.isLeaf(n) {
+		// replace the element with its in-order predecessor, the largest
+		// key of the left subtree, and delete that one from its leaf instead
+		leaf := n.Children[index]
+		for !t.isLeaf(leaf) {
+			leaf = leaf.Children[len(leaf.Children)-1]
+		}
+
+		n.Elements[index] = leaf.Elements[len(leaf.Elements)-1]
+		n, index = leaf, len(leaf.Elements)-1
+	}
+
+	t.removeFromLeaf(n, index)
+	return ele
+}
+
+// removeFromLeaf deletes the element at index from the leaf n and restores
+// the minimum fill of the nodes on the path to the root.
+func (t *Tree[K, V]) removeFromLeaf(n *Node[K, V], index int) {
+	n.Elements = append(n.Elements[:index], n.Elements[index+1:]...)
+	for p := n; p != nil; p = p.Parent {
+		p.subtreeSize--
+	}
+
+	t.size--
+	t.deletes++
+	t.rebalance(n)
+}
+
+// rebalance fixes an underflow of n by borrowing a key from a sibling
+// through the parent, or else by merging n with a sibling. A merge takes a
+// separator away from the parent, so the underflow may propagate up to
+// the root.
+func (t *Tree[K, V]) rebalance(n *Node[K, V]) {
+	if t.isRoot(n) {
+		t.CollapseRoot()
+		return
+	}
+
+	if len(n.Elements) >= t.minElements() {
+		return
+	}
+
+	parent := n.Parent
+	ci := t.childIndex(parent, n)
+
+	if ci > 0 && len(parent.Children[ci-1].Elements) > t.minElements() {
+		t.borrowFromLeft(parent, ci)
+		return
+	}
+
+	if ci < len(parent.Children)-1 && len(parent.Children[ci+1].Elements) > t.minElements() {
+		t.borrowFromRight(parent, ci)
+		return
+	}
+
+	if ci > 0 {
+		t.merge(parent, ci-1)
+	} else {
+		t.merge(parent, ci)
+	}
+
+	t.rebalance(parent)
+}
+
+// CollapseRoot drops a root that has run out of elements, promoting its
+// only child to root and reducing the height by one. An empty leaf root
+// leaves the tree empty. Remove collapses the root on its own; this is for
+// trees whose nodes were edited by hand.
+func (t *Tree[K, V]) CollapseRoot() {
+	if t.Root == nil || len(t.Root.Elements) > 0 {
+		return
+	}
+
+	t.mutate()
+	if t.isLeaf(t.Root) {
+		t.Root = nil
+		return
+	}
+
+	t.Root = t.Root.Children[0]
+	t.Root.Parent = nil
+}
+
+// childIndex returns the position of c in parent's children.
+func (t *Tree[K, V]) childIndex(parent, c *Node[K, V]) int {
+	for i, child := range parent.Children {
+		if child == c {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// borrowFromLeft rotates the largest key of the left sibling of
+// parent.Children[ci] through the parent into that child.
+func (t *Tree[K, V]) borrowFromLeft(parent *Node[K, V], ci int) {
+	n, left := parent.Children[ci], parent.Children[ci-1]
+
+	n.Elements = append(n.Elements, nil)
+	copy(n.Elements[1:], n.Elements)
+	n.Elements[0] = parent.Elements[ci-1]
+
+	last := len(left.Elements) - 1
+	parent.Elements[ci-1] = left.Elements[last]
+	left.Elements = left.Elements[:last]
+
+	if !t.isLeaf(left) {
+		last := len(left.Children) - 1
+		c := left.Children[last]
+		left.Children = left.Children[:last]
+
+		n.Children = append(n.Children, nil)
+		copy(n.Children[1:], n.Children)
+		n.Children[0] = c
+		c.Parent = n
+	}
+
+	t.recount(n)
+	t.recount(left)
+}
+
+// borrowFromRight rotates the smallest key of the right sibling of
+// parent.Children[ci] through the parent into that child.
+func (t *Tree[K, V]) borrowFromRight(parent *Node[K, V], ci int) {
+	n, right := parent.Children[ci], parent.Children[ci+1]
+
+	n.Elements = append(n.Elements, parent.Elements[ci])
+	parent.Elements[ci] = right.Elements[0]
+	right.Elements = append(right.Elements[:0], right.Elements[1:]...)
+
+	if !t.isLeaf(right) {
+		c := right.Children[0]
+		right.Children = append(right.Children[:0], right.Children[1:]...)
+
+		n.Children = append(n.Children, c)
+		c.Parent = n
+	}
+
+	t.recount(n)
+	t.recount(right)
+}
+
+// merge folds parent.Children[i+1] and the separator between the two
+// children into parent.Children[i].
+func (t *Tree[K, V]) merge(parent *Node[K, V], i int) {
+	left, right := parent.Children[i], parent.Children[i+1]
+
+	left.Elements = append(left.Elements, parent.Elements[i])
+	left.Elements = append(left.Elements, right.Elements...)
+	for _, c := range right.Children {
+		c.Parent = left
+	}
+	left.Children = append(left.Children, right.Children...)
+	left.subtreeSize += 1 + right.subtreeSize
+
+	parent.Elements = append(parent.Elements[:i], parent.Elements[i+1:]...)
+	parent.Children = append(parent.Children[:i+1], parent.Children[i+2:]...)
+}
@@ -1,7 +1,12 @@
 package ntree
 
 import (
+	"bytes"
+	"fmt"
+	"math/rand"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -42,6 +47,22 @@ func TestGetElementNotFound(t *testing.T) {
 	assert.False(t, found, "key 16 should not be found")
 }
 
+func TestGetEntry(t *testing.T) {
+	tr := exampleTree()
+
+	entry, found := tr.GetEntry(6)
+	assert.True(t, found, "key 6 should be found")
+	assert.Equal(t, Entry[int, string]{Key: 6, Value: "f"}, entry)
+
+	entry.Value = "changed"
+	value, _ := tr.Get(6)
+	assert.Equal(t, "f", value, "the entry should be a copy")
+
+	entry, found = tr.GetEntry(16)
+	assert.False(t, found, "key 16 should not be found")
+	assert.Equal(t, Entry[int, string]{}, entry, "a miss should return the zero Entry")
+}
+
 func TestSize(t *testing.T) {
 	tr := exampleTree()
 	assert.Equal(t, tr.Size(), 9, "size should be 9")
@@ -52,7 +73,721 @@ func TestHeight(t *testing.T) {
 	assert.Equal(t, tr.Height(), 2, "height should be 2")
 }
 
+func TestHeightDefinition(t *testing.T) {
+	tr := New[int, string](3)
+	assert.Equal(t, 0, tr.Height(), "empty tree has height 0")
+
+	tr.Put(1, "a")
+	assert.Equal(t, 1, tr.Height(), "a single leaf root has height 1")
+
+	tr.Put(2, "b")
+	assert.Equal(t, 1, tr.Height(), "a full leaf root still has height 1")
+
+	tr.Put(3, "c")
+	assert.Equal(t, 2, tr.Height(), "splitting the root adds a level")
+
+	// order 3 trees of height h hold at most 3^h-1 keys
+	for key := 4; key <= 27; key++ {
+		tr.Put(key, "")
+	}
+	assert.Equal(t, 4, tr.Height(), "27 keys in an order 3 tree need 4 levels")
+	assert.Len(t, tr.LevelSizes(), tr.Height(), "height should count every level")
+
+	tr.Clear()
+	assert.Equal(t, 0, tr.Height(), "cleared tree has height 0")
+}
+
 func TestPrint(t *testing.T) {
 	tr := exampleTree()
 	tr.Print(os.Stdout)
 }
+
+func TestString(t *testing.T) {
+	tr := exampleTree()
+	assert.Equal(t, "[3 6]\n[1 2] [4 5] [7 8 9]", tr.String(), "one line per level, one bracket per node")
+
+	var buf bytes.Buffer
+	tr.Print(&buf)
+	assert.Equal(t, tr.String()+"\n", buf.String(), "Print should write the String form")
+
+	assert.Equal(t, "<empty>", New[int, string](3).String(), "empty tree has a stable string")
+
+	words := New[string, int](3)
+	words.Put("b", 1)
+	assert.Equal(t, "[b]", words.String(), "a single leaf root is one node")
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	tr := exampleTree()
+	eq := func(a, b string) bool { return a == b }
+
+	assert.True(t, tr.CompareAndSwap(3, "c", "z", eq), "matching swap should succeed")
+	value, _ := tr.Get(3)
+	assert.Equal(t, "z", value, "value should be swapped")
+
+	assert.False(t, tr.CompareAndSwap(4, "x", "y", eq), "non-matching swap should fail")
+	value, _ = tr.Get(4)
+	assert.Equal(t, "d", value, "value should be unchanged")
+
+	assert.False(t, tr.CompareAndSwap(42, "", "y", eq), "absent key should not swap")
+}
+
+func TestExtractMatching(t *testing.T) {
+	tr := exampleTree()
+
+	extracted := tr.ExtractMatching(func(key int, _ string) bool { return key > 5 })
+	assert.Equal(t, []Element[int, string]{{6, "f"}, {7, "g"}, {8, "h"}, {9, "i"}}, extracted,
+		"matching elements should be returned in order")
+	assert.Equal(t, 5, tr.Size(), "size should drop by the extracted count")
+
+	for key := 6; key <= 9; key++ {
+		_, found := tr.Get(key)
+		assert.False(t, found, "extracted key should be gone")
+	}
+	for key := 1; key <= 5; key++ {
+		_, found := tr.Get(key)
+		assert.True(t, found, "remaining key should still be found")
+	}
+}
+
+func TestSplitDoesNotAliasSiblings(t *testing.T) {
+	tr := New[int, int](5)
+	keys := []int{1, 2, 3, 4, 5, 0, -1}
+	for _, k := range keys {
+		tr.Put(k, k)
+	}
+
+	for _, k := range keys {
+		value, found := tr.Get(k)
+		assert.True(t, found, "key should be found after growing the left half of a split")
+		assert.Equal(t, k, value, "value should be intact")
+	}
+}
+
+// TestInternalChildrenStayInSync replays inserts and removes that split
+// internal nodes and then grow their left halves, running Validate after
+// every operation, so a node whose children fall out of step with its
+// elements is caught where it happens. Splits have always copied the
+// Children halves. The Elements halves used to share a backing array, and
+// with that aliasing back this sequence breaks key order at m=3.
+func TestInternalChildrenStayInSync(t *testing.T) {
+	for _, m := range []int{3, 4, 5} {
+		tr := New[int, int](m)
+		check := func(op string, key int) {
+			t.Helper()
+			if err := tr.Validate(); err != nil {
+				t.Fatalf("m=%d after %s(%d): %v", m, op, key, err)
+			}
+		}
+
+		for key := 100; key < 160; key += 2 {
+			tr.Put(key, key)
+			check("Put", key)
+		}
+		for key := 99; key > 0; key -= 3 {
+			tr.Put(key, key)
+			check("Put", key)
+		}
+		for key := 101; key < 160; key += 4 {
+			tr.Put(key, key)
+			check("Put", key)
+		}
+		for key := 100; key < 160; key += 6 {
+			tr.Remove(key)
+			check("Remove", key)
+		}
+		for key := 1; key < 100; key += 5 {
+			tr.Put(key, key)
+			check("Put", key)
+		}
+	}
+}
+
+func TestPutIf(t *testing.T) {
+	tr := New[string, int](3)
+	larger := func(value int) func(int, bool) bool {
+		return func(existing int, exists bool) bool {
+			return !exists || value > existing
+		}
+	}
+
+	assert.True(t, tr.PutIf("a", 5, larger(5)), "absent key should be written")
+	assert.False(t, tr.PutIf("a", 3, larger(3)), "smaller value should be rejected")
+	value, _ := tr.Get("a")
+	assert.Equal(t, 5, value, "rejected write should leave the value unchanged")
+
+	assert.True(t, tr.PutIf("a", 8, larger(8)), "larger value should be written")
+	value, _ = tr.Get("a")
+	assert.Equal(t, 8, value, "value should be overwritten")
+}
+
+func TestCloneIterationIdentical(t *testing.T) {
+	tr := exampleTree()
+	c := tr.Clone()
+
+	assert.True(t, tr.IsStructurallyEqual(c), "clone should have the same shape")
+
+	original, cloned := tr.elements(), c.elements()
+	assert.Len(t, cloned, len(original), "clone should hold the same number of elements")
+	for i := range original {
+		assert.Equal(t, *original[i], *cloned[i], "elements should match at every step")
+		assert.NotSame(t, original[i], cloned[i], "elements should be freshly allocated")
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	tr := exampleTree()
+
+	batch := []Element[int, string]{{0, "z"}, {4, "D"}, {5, "E"}, {10, "j"}, {11, "k"}, {12, "l"}, {13, "m"}}
+	tr.MergeSorted(batch)
+
+	assert.Equal(t, 14, tr.Size(), "new keys should be added, overlapping keys overwritten")
+	want := map[int]string{0: "z", 1: "a", 2: "b", 3: "c", 4: "D", 5: "E", 6: "f", 7: "g",
+		8: "h", 9: "i", 10: "j", 11: "k", 12: "l", 13: "m"}
+	for key, value := range want {
+		got, found := tr.Get(key)
+		assert.True(t, found, "merged key should be present")
+		assert.Equal(t, value, got, "merged value should match")
+	}
+
+	big := New[int, int](4)
+	elements := make([]Element[int, int], 1000)
+	for i := range elements {
+		elements[i] = Element[int, int]{Key: i * 2, Value: i}
+	}
+	big.MergeSorted(elements)
+	assert.Equal(t, 1000, big.Size(), "bulk merge into an empty tree should insert everything")
+	assert.Equal(t, big.Size(), len(big.elements()), "every element should be reachable")
+}
+
+func benchmarkBatch(n int) []Element[int, int] {
+	elements := make([]Element[int, int], n)
+	for i := range elements {
+		elements[i] = Element[int, int]{Key: i, Value: i}
+	}
+
+	return elements
+}
+
+func BenchmarkMergeSorted(b *testing.B) {
+	elements := benchmarkBatch(10000)
+	for i := 0; i < b.N; i++ {
+		New[int, int](32).MergeSorted(elements)
+	}
+}
+
+func BenchmarkPutSorted(b *testing.B) {
+	elements := benchmarkBatch(10000)
+	for i := 0; i < b.N; i++ {
+		t := New[int, int](32)
+		for _, e := range elements {
+			t.Put(e.Key, e.Value)
+		}
+	}
+}
+
+func TestPutIfAbsent(t *testing.T) {
+	tr := exampleTree()
+	overwrites := tr.OverwriteCount()
+
+	actual, inserted := tr.PutIfAbsent(5, "x")
+	assert.False(t, inserted, "present key should not be inserted")
+	assert.Equal(t, "e", actual, "the stored value should be returned")
+	v, _ := tr.Get(5)
+	assert.Equal(t, "e", v, "the stored value should be kept")
+	actual, inserted = tr.PutIfAbsent(3, "x")
+	assert.False(t, inserted, "keys in internal nodes should be found too")
+	assert.Equal(t, "c", actual)
+	assert.Equal(t, overwrites, tr.OverwriteCount(), "nothing should be overwritten")
+
+	for key := 10; key < 30; key++ {
+		actual, inserted = tr.PutIfAbsent(key, fmt.Sprint(key))
+		assert.True(t, inserted, "absent key %d should be inserted", key)
+		assert.Equal(t, fmt.Sprint(key), actual)
+		assert.NoError(t, tr.Validate())
+	}
+	assert.Equal(t, 29, tr.Size())
+
+	empty := New[int, string](3)
+	actual, inserted = empty.PutIfAbsent(1, "a")
+	assert.True(t, inserted, "an empty tree should take the first key")
+	assert.Equal(t, "a", actual)
+	assert.Equal(t, 1, empty.Size())
+}
+
+func TestGetOrPut(t *testing.T) {
+	tr := exampleTree()
+	calls := 0
+	factory := func() string {
+		calls++
+		return "new"
+	}
+
+	value, loaded := tr.GetOrPut(4, factory)
+	assert.True(t, loaded, "present key should be loaded")
+	assert.Equal(t, "d", value)
+	assert.Equal(t, 0, calls, "factory should not be called on a hit")
+
+	value, loaded = tr.GetOrPut(10, factory)
+	assert.False(t, loaded, "absent key should be stored")
+	assert.Equal(t, "new", value)
+	assert.Equal(t, 1, calls, "factory should be called once on a miss")
+
+	value, loaded = tr.GetOrPut(10, factory)
+	assert.True(t, loaded, "the stored value should be found next time")
+	assert.Equal(t, "new", value)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 10, tr.Size())
+	assert.NoError(t, tr.Validate())
+}
+
+func TestUpdate(t *testing.T) {
+	tr := New[string, int](3)
+	incr := func(v int, _ bool) int { return v + 1 }
+	for _, word := range strings.Fields("a b a c b a d e f a") {
+		tr.Update(word, incr)
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, tr.Keys())
+	assert.Equal(t, []int{4, 2, 1, 1, 1, 1}, tr.Values(), "each word should be counted")
+	assert.NoError(t, tr.Validate())
+
+	var sawFound []bool
+	got := tr.Update("g", func(old int, found bool) int {
+		sawFound = append(sawFound, found)
+		return old + 10
+	})
+	assert.Equal(t, 10, got, "absent keys should start from the zero value")
+	got = tr.Update("g", func(old int, found bool) int {
+		sawFound = append(sawFound, found)
+		return old * 2
+	})
+	assert.Equal(t, 20, got, "the new value should be returned")
+	assert.Equal(t, []bool{false, true}, sawFound, "fn should be told whether the key existed")
+}
+
+func TestPutAll(t *testing.T) {
+	tr := exampleTree()
+	tr.PutAll([]int{12, 0, 5, 10, 12, 11}, []string{"x", "z", "E", "j", "l", "k"})
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, tr.Keys(), "all keys should be inserted")
+	v, _ := tr.Get(5)
+	assert.Equal(t, "E", v, "existing keys should be overwritten")
+	v, _ = tr.Get(12)
+	assert.Equal(t, "l", v, "the later of repeated keys should win")
+	assert.NoError(t, tr.Validate())
+	assertSubtreeSizes(t, tr.Root)
+
+	assert.Panics(t, func() { tr.PutAll([]int{1, 2}, []string{"a"}) }, "mismatched lengths should panic")
+}
+
+func benchmarkShuffled(n int) ([]int, []int) {
+	rng := rand.New(rand.NewSource(1))
+	keys := rng.Perm(n)
+	return keys, slices.Clone(keys)
+}
+
+func BenchmarkPutAll(b *testing.B) {
+	keys, values := benchmarkShuffled(10000)
+	for i := 0; i < b.N; i++ {
+		New[int, int](32).PutAll(keys, values)
+	}
+}
+
+func BenchmarkPutShuffled(b *testing.B) {
+	keys, values := benchmarkShuffled(10000)
+	for i := 0; i < b.N; i++ {
+		t := New[int, int](32)
+		for j := range keys {
+			t.Put(keys[j], values[j])
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	build := func(m, from, to int) *Tree[int, int] {
+		tr := New[int, int](m)
+		for key := from; key < to; key++ {
+			tr.Put(key, key)
+		}
+		return tr
+	}
+
+	a, b := build(3, 0, 100), build(7, 50, 150)
+	a.Merge(b, func(x, y int) int { return x + y })
+	assert.NoError(t, a.Validate())
+	assert.Equal(t, 150, a.Size(), "the union should hold every key once")
+	for key := 0; key < 150; key++ {
+		want := key
+		if key >= 50 && key < 100 {
+			want = 2 * key
+		}
+		v, _ := a.Get(key)
+		assert.Equal(t, want, v, "key %d", key)
+	}
+	assert.Equal(t, 100, b.Size(), "other should be untouched")
+
+	a, b = build(4, 0, 10), New[int, int](4)
+	for key := 5; key < 15; key++ {
+		b.Put(key, -key)
+	}
+	a.Merge(b, nil)
+	v, _ := a.Get(7)
+	assert.Equal(t, -7, v, "other's value should win without onConflict")
+	v, _ = a.Get(2)
+	assert.Equal(t, 2, v, "keys only in t should keep their value")
+	assert.Equal(t, 15, a.Size())
+}
+
+func TestSnapshot(t *testing.T) {
+	tr := exampleTree()
+	snap := tr.Snapshot()
+	tr.Put(10, "j")
+	tr.Remove(1)
+
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, snap.Keys(), "the snapshot should not see later writes")
+	value, found := snap.Get(1)
+	assert.True(t, found, "Get should work on a snapshot")
+	assert.Equal(t, "a", value)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, iteratorKeys(snap.Iterator()), "Iterator should work")
+	assert.Equal(t, []int{3, 4, 5}, iteratorKeys(snap.Range(3, 6)), "Range should work")
+	assert.NoError(t, snap.Validate())
+
+	gob, _ := exampleTree().GobEncode()
+	mutations := map[string]func(){
+		"Put":         func() { snap.Put(0, "z") },
+		"Remove":      func() { snap.Remove(5) },
+		"PopMax":      func() { snap.PopMax() },
+		"Update":      func() { snap.Update(5, func(string, bool) string { return "x" }) },
+		"PutAll":      func() { snap.PutAll([]int{0}, []string{"z"}) },
+		"DeleteRange": func() { snap.DeleteRange(2, 4) },
+		"Clear":       func() { snap.Clear() },
+		"Reset":       func() { snap.Reset() },
+		"Compact":     func() { snap.Compact() },
+		"ReplaceAll":  func() { snap.ReplaceAll(nil) },
+		"GobDecode":   func() { _ = snap.GobDecode(gob) },
+	}
+	for name, mutate := range mutations {
+		assert.PanicsWithError(t, "ntree: snapshot is read-only", mutate, name)
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, snap.Keys(), "%s should leave the snapshot intact", name)
+	}
+	assert.NoError(t, snap.Validate())
+	assert.Equal(t, 5, snap.m, "a rejected GobDecode should keep the order")
+
+	thawed := snap.Clone()
+	thawed.Put(0, "z")
+	assert.Equal(t, 10, thawed.Size(), "a clone of a snapshot should be writable")
+	assert.Equal(t, 9, snap.Size())
+}
+
+func TestCompact(t *testing.T) {
+	tr := New[int, int](5)
+	for key := 0; key < 100; key++ {
+		tr.Put(key, key)
+	}
+
+	before := tr.NodeCount()
+	tr.Compact()
+	assert.Less(t, tr.NodeCount(), before, "compaction should use fewer nodes")
+	assert.Equal(t, 100, tr.Size(), "compaction should keep every element")
+	for key := 0; key < 100; key++ {
+		value, found := tr.Get(key)
+		assert.True(t, found, "key should survive compaction")
+		assert.Equal(t, key, value, "value should survive compaction")
+	}
+}
+
+func TestSetAutoCompact(t *testing.T) {
+	tr := New[int, int](5)
+	for key := 0; key < 100; key++ {
+		tr.Put(key, key)
+	}
+	tr.SetAutoCompact(5)
+
+	removed := tr.ExtractMatching(func(key, _ int) bool { return key%20 == 0 })
+	assert.Len(t, removed, 5, "five keys should be deleted")
+
+	before := tr.NodeCount()
+	tr.Put(1000, 1000)
+	assert.Less(t, tr.NodeCount(), before, "the next mutation should compact the tree")
+	assert.Equal(t, 96, tr.Size(), "compaction should keep every element")
+	assert.Equal(t, 0, tr.deletes, "the delete counter should reset after compacting")
+}
+
+func TestOverwriteCount(t *testing.T) {
+	tr := New[int, string](3)
+	for key := 1; key <= 5; key++ {
+		tr.Put(key, "v")
+	}
+	assert.Equal(t, 0, tr.OverwriteCount(), "fresh keys are not overwrites")
+
+	tr.Put(3, "x")
+	tr.Put(3, "y")
+	assert.Equal(t, 2, tr.OverwriteCount(), "each put of an existing key is an overwrite")
+}
+
+func TestRemove(t *testing.T) {
+	tr := exampleTree()
+
+	assert.False(t, tr.Remove(42), "absent key should not be removed")
+	assert.True(t, tr.Remove(8), "leaf key should be removed")
+	assert.True(t, tr.Remove(3), "internal key should be removed")
+	assert.True(t, tr.Remove(1), "key from a minimum leaf should be removed")
+	assert.Equal(t, 6, tr.Size(), "size should drop with every removal")
+
+	for _, key := range []int{1, 3, 8} {
+		_, found := tr.Get(key)
+		assert.False(t, found, "removed key should be gone")
+	}
+	for _, key := range []int{2, 4, 5, 6, 7, 9} {
+		_, found := tr.Get(key)
+		assert.True(t, found, "remaining key should be found")
+	}
+
+	for _, key := range []int{2, 4, 5, 6, 7, 9} {
+		assert.True(t, tr.Remove(key), "remaining key should be removed")
+	}
+	assert.True(t, tr.Empty(), "tree should be empty")
+	assert.Nil(t, tr.Root, "empty tree should have no root")
+}
+
+func TestPopMinMax(t *testing.T) {
+	tr := New[int, int](4)
+	for _, key := range rand.New(rand.NewSource(1)).Perm(200) {
+		tr.Put(key, key*10)
+	}
+
+	key, value, ok := tr.PopMax()
+	assert.True(t, ok)
+	assert.Equal(t, 199, key, "PopMax should take the largest key")
+	assert.Equal(t, 1990, value)
+
+	for want := 0; want < 199; want++ {
+		key, value, ok = tr.PopMin()
+		assert.True(t, ok)
+		assert.Equal(t, want, key, "PopMin should drain keys in ascending order")
+		assert.Equal(t, want*10, value)
+		assert.NoError(t, tr.Validate())
+	}
+
+	assert.True(t, tr.Empty(), "the tree should be drained")
+	_, _, ok = tr.PopMin()
+	assert.False(t, ok, "PopMin on an empty tree should fail")
+	_, _, ok = tr.PopMax()
+	assert.False(t, ok, "PopMax on an empty tree should fail")
+}
+
+func TestDeleteRange(t *testing.T) {
+	build := func() *Tree[int, int] {
+		tr := New[int, int](4)
+		for key := 0; key < 1000; key += 2 {
+			tr.Put(key, key)
+		}
+		return tr
+	}
+
+	for _, tc := range []struct {
+		name    string
+		lo, hi  int
+		removed int
+	}{
+		{"a few keys", 101, 119, 9},
+		{"bounds present", 100, 120, 11},
+		{"most of the tree", 50, 949, 450},
+		{"everything", -10, 2000, 500},
+		{"a gap", 101, 101, 0},
+		{"lo > hi", 500, 400, 0},
+	} {
+		tr := build()
+		assert.Equal(t, tc.removed, tr.DeleteRange(tc.lo, tc.hi), tc.name)
+		assert.Equal(t, 500-tc.removed, tr.Size(), tc.name)
+		assert.NoError(t, tr.Validate(), tc.name)
+
+		for key := 0; key < 1000; key += 2 {
+			inRange := key >= tc.lo && key <= tc.hi
+			assert.Equal(t, !inRange, tr.Contains(key), "%s: key %d", tc.name, key)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	tr := New[int, int](3)
+	for key := 0; key < 100; key++ {
+		tr.Put(key, key)
+	}
+	root, leaf := tr.Root, tr.Root.Children[0]
+	for !tr.isLeaf(leaf) {
+		leaf = leaf.Children[0]
+	}
+
+	tr.Reset()
+	assert.True(t, tr.Empty(), "the tree should be empty")
+	assert.Nil(t, tr.Root)
+	assert.Equal(t, 0, tr.Height())
+	assert.Nil(t, root.Children, "the old root should drop its children")
+	assert.Nil(t, leaf.Parent, "old leaves should drop their parents")
+	assert.Nil(t, leaf.Elements, "old leaves should drop their elements")
+
+	tr.Put(1, 1)
+	assert.Equal(t, 1, tr.Size(), "a reset tree should accept writes")
+	New[int, int](3).Reset()
+}
+
+func TestRemoveUnderflowToRoot(t *testing.T) {
+	for _, m := range []int{3, 4, 5} {
+		tr := New[int, int](m)
+		for key := 0; key < 200; key++ {
+			tr.Put(key, key)
+		}
+
+		height := tr.Height()
+		assert.GreaterOrEqual(t, height, 3, "m=%d: tree should have several levels", m)
+
+		// removing from the front keeps the leftmost leaves at minimum
+		// fill, so merges cascade up until the root collapses
+		removed := 0
+		for tr.Height() == height {
+			assert.True(t, tr.Remove(removed), "m=%d: key %d should be removed", m, removed)
+			removed++
+		}
+		assert.Equal(t, height-1, tr.Height(), "m=%d: root collapse should drop one level", m)
+		assert.Equal(t, 200-removed, tr.Size(), "m=%d: size should track removals", m)
+		assert.NoError(t, tr.Validate(), "m=%d: tree should stay valid", m)
+
+		for key := 0; key < 200; key++ {
+			value, found := tr.Get(key)
+			assert.Equal(t, key >= removed, found, "m=%d: key %d presence", m, key)
+			if found {
+				assert.Equal(t, key, value, "m=%d: value of key %d", m, key)
+			}
+		}
+
+		for key := 199; key >= removed; key-- {
+			assert.True(t, tr.Remove(key), "m=%d: key %d should be removed", m, key)
+		}
+		assert.True(t, tr.Empty(), "m=%d: tree should drain completely", m)
+		assert.Equal(t, 0, tr.Height(), "m=%d: drained tree should have no levels", m)
+	}
+}
+
+func countElements[K comparable, V any](n *Node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+
+	count := len(n.Elements)
+	for _, c := range n.Children {
+		count += countElements(c)
+	}
+
+	return count
+}
+
+func assertSubtreeSizes[K comparable, V any](t *testing.T, n *Node[K, V]) {
+	if n == nil {
+		return
+	}
+
+	assert.Equal(t, countElements(n), n.SubtreeSize(), "cached subtree size should match a recount")
+	for _, c := range n.Children {
+		assertSubtreeSizes(t, c)
+	}
+}
+
+func TestSubtreeSize(t *testing.T) {
+	tr := New[int, int](4)
+	for i := 0; i < 500; i++ {
+		tr.Put((i*7919)%1000, i)
+	}
+	assertSubtreeSizes(t, tr.Root)
+	assert.Equal(t, tr.Size(), tr.Root.SubtreeSize(), "root should count every element")
+
+	for i := 0; i < 500; i += 3 {
+		tr.Remove((i * 7919) % 1000)
+	}
+	assertSubtreeSizes(t, tr.Root)
+	assert.Equal(t, tr.Size(), tr.Root.SubtreeSize(), "root should count every element after removals")
+
+	tr.Compact()
+	assertSubtreeSizes(t, tr.Root)
+	assertSubtreeSizes(t, tr.Clone().Root)
+
+	var nilNode *Node[int, int]
+	assert.Equal(t, 0, nilNode.SubtreeSize(), "a nil node holds nothing")
+}
+
+func TestCollapseRoot(t *testing.T) {
+	tr := exampleTree()
+	height := tr.Height()
+
+	child := tr.Root
+	tr.Root = &Node[int, string]{Children: []*Node[int, string]{child}, subtreeSize: child.subtreeSize}
+	child.Parent = tr.Root
+	assert.Equal(t, height+1, tr.Height(), "degenerate root adds a level")
+
+	tr.CollapseRoot()
+	assert.Equal(t, height, tr.Height(), "collapsing should drop the degenerate level")
+	assert.Same(t, child, tr.Root, "the only child should become the root")
+	assert.Nil(t, tr.Root.Parent, "the new root should have no parent")
+	assert.NoError(t, tr.Validate(), "collapsed tree should be valid")
+	for key := 1; key <= 9; key++ {
+		_, found := tr.Get(key)
+		assert.True(t, found, "key should still be found")
+	}
+
+	tr.CollapseRoot()
+	assert.Same(t, child, tr.Root, "a root with elements is left alone")
+}
+
+func TestNewOrder(t *testing.T) {
+	for _, m := range []int{0, 1, 2} {
+		assert.PanicsWithError(t, fmt.Sprintf("ntree: order m must be >= 3, got %d", m), func() {
+			New[int, string](m)
+		}, "order %d should be rejected", m)
+	}
+
+	assert.NotPanics(t, func() {
+		tr := New[int, string](3)
+		for key := 0; key < 20; key++ {
+			tr.Put(key, "")
+		}
+	}, "order 3 should work")
+}
+
+func TestContains(t *testing.T) {
+	tr := exampleTree()
+	assert.True(t, tr.Contains(3), "key 3 should be present")
+	assert.True(t, tr.Contains(9), "key 9 should be present")
+	assert.False(t, tr.Contains(16), "key 16 should be absent")
+
+	assert.False(t, New[int, string](3).Contains(1), "empty tree contains nothing")
+
+	tr.Clear()
+	assert.False(t, tr.Contains(3), "cleared tree contains nothing")
+}
+
+func TestCloneIndependent(t *testing.T) {
+	tr := exampleTree()
+	c := tr.Clone()
+
+	assert.NoError(t, c.Validate(), "clone should be a valid tree")
+	assert.Equal(t, tr.m, c.m, "order should be carried over")
+	assert.NotNil(t, c.Comparator, "comparator should be carried over")
+	for _, child := range c.Root.Children {
+		assert.Same(t, c.Root, child.Parent, "parent pointers should refer to the cloned nodes")
+	}
+
+	for key := 10; key <= 30; key++ {
+		c.Put(key, "new")
+	}
+	c.Put(3, "changed")
+	c.Remove(1)
+	c.Remove(8)
+
+	assert.Equal(t, 9, tr.Size(), "original size should be unchanged")
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, tr.Keys(), "original keys should be unchanged")
+	value, _ := tr.Get(3)
+	assert.Equal(t, "c", value, "original values should be unchanged")
+	assert.NoError(t, tr.Validate(), "original should still be valid")
+	assert.False(t, tr.IsStructurallyEqual(c), "the mutated clone should have diverged")
+}
@@ -0,0 +1,35 @@
+package ntree
+
+import "io"
+
+// Reader delivers the elements of a tree in ascending key order in chunks
+// of the caller's choosing, mirroring io.Reader. The tree must not be
+// modified while it is being read.
+type Reader[K comparable, V any] struct {
+	c *cursor[K, V]
+}
+
+// Reader returns a Reader positioned at the smallest key of the tree
+func (t *Tree[K, V]) Reader() *Reader[K, V] {
+	return &Reader[K, V]{c: newCursor(t)}
+}
+
+// Read fills buf with the next elements and returns how many it wrote.
+// Once every element has been delivered it returns 0 and io.EOF.
+func (r *Reader[K, V]) Read(buf []Element[K, V]) (int, error) {
+	n := 0
+	for ; n < len(buf); n++ {
+		e := r.c.next()
+		if e == nil {
+			break
+		}
+
+		buf[n] = *e
+	}
+
+	if n == 0 && len(buf) > 0 {
+		return 0, io.EOF
+	}
+
+	return n, nil
+}
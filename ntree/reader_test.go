@@ -0,0 +1,31 @@
+package ntree
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReader(t *testing.T) {
+	r := exampleTree().Reader()
+	buf := make([]Element[int, string], 4)
+
+	var chunks [][]int
+	for {
+		n, err := r.Read(buf)
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		chunks = append(chunks, elementKeys(buf[:n]))
+	}
+	assert.Equal(t, [][]int{{1, 2, 3, 4}, {5, 6, 7, 8}, {9}}, chunks, "elements should arrive in chunks of 4")
+
+	n, err := r.Read(buf)
+	assert.Equal(t, 0, n, "an exhausted reader returns nothing")
+	assert.Equal(t, io.EOF, err, "an exhausted reader keeps returning EOF")
+
+	_, err = New[int, string](3).Reader().Read(buf)
+	assert.Equal(t, io.EOF, err, "an empty tree is immediately exhausted")
+}
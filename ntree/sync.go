@@ -0,0 +1,61 @@
+package ntree
+
+import (
+	"cmp"
+	"sync"
+)
+
+// SyncTree wraps a Tree with a read-write mutex so it can be shared between
+// goroutines. Writes take the exclusive lock and reads the shared one.
+type SyncTree[K comparable, V any] struct {
+	mu   sync.RWMutex
+	tree *Tree[K, V]
+}
+
+// NewSync creates a new SyncTree of order m, see New
+func NewSync[K cmp.Ordered, V any](m int) *SyncTree[K, V] {
+	return &SyncTree[K, V]{tree: New[K, V](m)}
+}
+
+// Put inserts or updates a key-value pair
+func (s *SyncTree[K, V]) Put(key K, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tree.Put(key, value)
+}
+
+// Get retrieves the value associated with the key
+func (s *SyncTree[K, V]) Get(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Get(key)
+}
+
+// Remove deletes the key, reporting whether it was present
+func (s *SyncTree[K, V]) Remove(key K) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.Remove(key)
+}
+
+// Size returns the number of elements
+func (s *SyncTree[K, V]) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Size()
+}
+
+// Contains reports whether the key is present
+func (s *SyncTree[K, V]) Contains(key K) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Contains(key)
+}
+
+// Stats returns the size, minimum and maximum keys, and height of the tree
+// under a single read lock, so the fields are consistent with each other
+func (s *SyncTree[K, V]) Stats() (size int, minKey, maxKey K, height int, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Stats()
+}
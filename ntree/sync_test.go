@@ -0,0 +1,43 @@
+package ntree
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSyncTreeConcurrent is meant to be run with -race.
+func TestSyncTreeConcurrent(t *testing.T) {
+	const workers, perWorker = 8, 500
+	s := NewSync[int, int](4)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				key := w*perWorker + i
+				s.Put(key, key)
+				if v, ok := s.Get(key); !ok || v != key {
+					t.Errorf("key %d: got %d, %v", key, v, ok)
+				}
+				s.Contains(key - 1)
+				if size, minKey, maxKey, _, ok := s.Stats(); !ok || size == 0 || minKey > maxKey {
+					t.Errorf("inconsistent stats: size %d, span [%d, %d], ok %v", size, minKey, maxKey, ok)
+				}
+				if i%2 == 1 {
+					s.Remove(key)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	assert.Equal(t, workers*perWorker/2, s.Size(), "every odd insert should have been removed")
+	for key := 0; key < workers*perWorker; key++ {
+		assert.Equal(t, key%2 == 0, s.Contains(key), "key %d", key)
+	}
+	assert.NoError(t, s.tree.Validate())
+}
@@ -0,0 +1,157 @@
+package ntree
+
+import "fmt"
+
+// StreamBatches invokes fn with consecutive batches of up to batchSize
+// elements in ascending key order. Streaming stops at the first error
+// returned by fn, which is handed back to the caller. A batchSize below 1
+// is rejected with an error before fn is called.
+func (t *Tree[K, V]) StreamBatches(batchSize int, fn func(batch []Element[K, V]) error) error {
+	if batchSize < 1 {
+		return fmt.Errorf("ntree: batch size must be >= 1, got %d", batchSize)
+	}
+
+	var err error
+	batch := make([]Element[K, V], 0, batchSize)
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		batch = append(batch, *e)
+		if len(batch) < batchSize {
+			return true
+		}
+
+		err = fn(batch)
+		batch = make([]Element[K, V], 0, batchSize)
+		return err == nil
+	})
+
+	if err != nil || len(batch) == 0 {
+		return err
+	}
+
+	return fn(batch)
+}
+
+// ForEach invokes fn for each element in ascending key order and stops as
+// soon as fn returns false, without visiting any further elements
+func (t *Tree[K, V]) ForEach(fn func(key K, value V) bool) {
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		return fn(e.Key, e.Value)
+	})
+}
+
+// WalkPairs invokes fn for each pair of consecutive elements in ascending
+// key order, starting with the first and second elements, and stops as
+// soon as fn returns false.
+func (t *Tree[K, V]) WalkPairs(fn func(prevKey K, prevVal V, curKey K, curVal V) bool) {
+	var prev *Element[K, V]
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		if prev == nil {
+			prev = e
+			return true
+		}
+
+		more := fn(prev.Key, prev.Value, e.Key, e.Value)
+		prev = e
+		return more
+	})
+}
+
+// FilterKeys returns, in ascending key order, the elements whose keys
+// satisfy pred. Every element is visited.
+func (t *Tree[K, V]) FilterKeys(pred func(K) bool) []Element[K, V] {
+	var matched []Element[K, V]
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		if pred(e.Key) {
+			matched = append(matched, *e)
+		}
+		return true
+	})
+
+	return matched
+}
+
+// Filter returns a new tree of the same order and comparator holding the
+// elements for which pred returns true. The kept elements are already in
+// order, so the result is bulk loaded rather than built with Put. t is not
+// modified.
+func (t *Tree[K, V]) Filter(pred func(K, V) bool) *Tree[K, V] {
+	var kept []*Element[K, V]
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		if pred(e.Key, e.Value) {
+			kept = append(kept, &Element[K, V]{Key: e.Key, Value: e.Value})
+		}
+		return true
+	})
+
+	filtered := &Tree[K, V]{Comparator: t.Comparator, m: t.m}
+	filtered.load(kept)
+	return filtered
+}
+
+// RoundRobinWalk visits every element once, taking one element at a time
+// from each subtree of the root in rotation until all are exhausted, and
+// stops as soon as fn returns false. Each root element is visited right
+// after the subtree to its left. The order is NOT sorted: it spreads the
+// visits across the key space instead.
+func (t *Tree[K, V]) RoundRobinWalk(fn func(K, V) bool) {
+	if t.Root == nil {
+		return
+	}
+
+	if t.isLeaf(t.Root) {
+		t.walk(t.Root, func(e *Element[K, V]) bool { return fn(e.Key, e.Value) })
+		return
+	}
+
+	cursors := make([]*cursor[K, V], len(t.Root.Children))
+	separators := make([]*Element[K, V], len(t.Root.Children))
+	for i, c := range t.Root.Children {
+		cursors[i] = openCursor(t)
+		cursors[i].pushLeft(c)
+		if i < len(t.Root.Elements) {
+			separators[i] = t.Root.Elements[i]
+		}
+	}
+
+	for active := len(cursors); active > 0; {
+		active = 0
+		for i, c := range cursors {
+			e := c.next()
+			if e == nil {
+				e, separators[i] = separators[i], nil
+			}
+			if e == nil {
+				continue
+			}
+
+			active++
+			if !fn(e.Key, e.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns all keys in ascending order. The result is empty but not
+// nil for an empty tree.
+func (t *Tree[K, V]) Keys() []K {
+	keys := make([]K, 0, t.Size())
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		keys = append(keys, e.Key)
+		return true
+	})
+
+	return keys
+}
+
+// Values returns all values in the same order as Keys, so the two slices
+// can be zipped together.
+func (t *Tree[K, V]) Values() []V {
+	values := make([]V, 0, t.Size())
+	t.walk(t.Root, func(e *Element[K, V]) bool {
+		values = append(values, e.Value)
+		return true
+	})
+
+	return values
+}
@@ -0,0 +1,172 @@
+package ntree
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamBatches(t *testing.T) {
+	tr := exampleTree()
+
+	var sizes []int
+	var keys []int
+	err := tr.StreamBatches(4, func(batch []Element[int, string]) error {
+		sizes = append(sizes, len(batch))
+		for _, e := range batch {
+			keys = append(keys, e.Key)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 4, 1}, sizes, "batches should hold up to 4 elements")
+	assert.Equal(t, tr.Size(), len(keys), "all elements should be streamed")
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, keys, "elements should stream in order")
+
+	calls := 0
+	errStop := errors.New("stop")
+	err = tr.StreamBatches(2, func(batch []Element[int, string]) error {
+		calls++
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop, "the first error should be returned")
+	assert.Equal(t, 1, calls, "streaming should abort after the error")
+
+	calls = 0
+	for _, size := range []int{0, -1} {
+		err = tr.StreamBatches(size, func(batch []Element[int, string]) error {
+			calls++
+			return nil
+		})
+		assert.Error(t, err, "batch size %d should be rejected", size)
+	}
+	assert.Zero(t, calls, "fn should not be called for an invalid batch size")
+}
+
+func TestForEach(t *testing.T) {
+	tr := exampleTree()
+
+	var keys []int
+	var values []string
+	tr.ForEach(func(key int, value string) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+		return true
+	})
+	assert.Equal(t, tr.Keys(), keys, "keys should be visited in ascending order")
+	assert.Equal(t, tr.Values(), values, "values should follow their keys")
+
+	keys = nil
+	tr.ForEach(func(key int, _ string) bool {
+		keys = append(keys, key)
+		return key < 4
+	})
+	assert.Equal(t, []int{1, 2, 3, 4}, keys, "returning false should stop the walk")
+}
+
+func TestWalkPairs(t *testing.T) {
+	tr := exampleTree()
+
+	var pairs [][2]int
+	tr.WalkPairs(func(prevKey int, _ string, curKey int, _ string) bool {
+		pairs = append(pairs, [2]int{prevKey, curKey})
+		return true
+	})
+	assert.Len(t, pairs, tr.Size()-1, "there should be one pair per consecutive elements")
+	assert.Equal(t, [2]int{1, 2}, pairs[0], "first pair should be (1, 2)")
+	assert.Equal(t, [2]int{8, 9}, pairs[len(pairs)-1], "last pair should be (8, 9)")
+
+	calls := 0
+	tr.WalkPairs(func(int, string, int, string) bool {
+		calls++
+		return calls < 3
+	})
+	assert.Equal(t, 3, calls, "walk should stop when fn returns false")
+}
+
+func TestFilterKeys(t *testing.T) {
+	tr := exampleTree()
+
+	even := tr.FilterKeys(func(key int) bool { return key%2 == 0 })
+	keys := make([]int, len(even))
+	for i, e := range even {
+		keys[i] = e.Key
+	}
+	assert.Equal(t, []int{2, 4, 6, 8}, keys, "only even keys should be returned in order")
+	assert.Equal(t, "b", even[0].Value, "values should travel with their keys")
+}
+
+func TestFilter(t *testing.T) {
+	tr := New[int, int](4)
+	for key := 0; key < 500; key++ {
+		tr.Put(key, key*key)
+	}
+	before := tr.String()
+
+	even := tr.Filter(func(key, value int) bool { return key%2 == 0 && value > 100 })
+	assert.NoError(t, even.Validate(), "the filtered tree should be valid")
+	assert.Equal(t, 244, even.Size())
+	even.ForEach(func(key, value int) bool {
+		assert.True(t, key%2 == 0 && key > 10, "key %d should not have been kept", key)
+		assert.Equal(t, key*key, value)
+		return true
+	})
+	assert.Equal(t, tr.m, even.m, "order should be carried over")
+
+	assert.Equal(t, before, tr.String(), "the original should be untouched")
+	assert.Equal(t, 500, tr.Size())
+
+	none := tr.Filter(func(int, int) bool { return false })
+	assert.True(t, none.Empty(), "rejecting everything should give an empty tree")
+	none.Put(1, 1)
+	assert.Equal(t, 1, none.Size(), "an empty result should accept writes")
+}
+
+func TestRoundRobinWalk(t *testing.T) {
+	tr := New[int, int](4)
+	for key := 0; key < 50; key++ {
+		tr.Put(key, key)
+	}
+
+	seen := map[int]int{}
+	var order []int
+	tr.RoundRobinWalk(func(key, _ int) bool {
+		seen[key]++
+		order = append(order, key)
+		return true
+	})
+	assert.Len(t, seen, tr.Size(), "every element should be visited")
+	for key, count := range seen {
+		assert.Equal(t, 1, count, "key %d should be visited exactly once", key)
+	}
+	assert.NotEqual(t, tr.elements()[1].Key, order[1], "visits should rotate across subtrees")
+
+	calls := 0
+	tr.RoundRobinWalk(func(int, int) bool {
+		calls++
+		return calls < 5
+	})
+	assert.Equal(t, 5, calls, "walk should stop when fn returns false")
+}
+
+func TestKeysValues(t *testing.T) {
+	tr := New[int, int](3)
+	for _, key := range []int{50, 10, 40, 20, 30, 60, 0, 70, 80, 90} {
+		tr.Put(key, key*2)
+	}
+	assert.Greater(t, tr.Height(), 2, "tree should have several levels")
+
+	keys, values := tr.Keys(), tr.Values()
+	assert.Equal(t, []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90}, keys, "keys should be ascending")
+	assert.Len(t, values, len(keys), "there should be one value per key")
+	for i, key := range keys {
+		assert.Equal(t, key*2, values[i], "values should line up with keys")
+	}
+
+	empty := New[int, int](3)
+	assert.NotNil(t, empty.Keys(), "keys of an empty tree should not be nil")
+	assert.Empty(t, empty.Keys(), "empty tree has no keys")
+	assert.NotNil(t, empty.Values(), "values of an empty tree should not be nil")
+	assert.Empty(t, empty.Values(), "empty tree has no values")
+}
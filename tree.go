@@ -0,0 +1,20 @@
+// Package tree defines the interface implemented by the trees in this
+// module, so algorithms can be written once and run against any of them.
+package tree
+
+import "io"
+
+// Tree is a tree storing one value per key
+type Tree[K comparable, V any] interface {
+	// Put inserts or updates a key-value pair
+	Put(key K, value V)
+	// Get retrieves the value associated with the key
+	Get(key K) (V, bool)
+	// Remove deletes the key, reporting whether it was present
+	Remove(key K) bool
+
+	Empty() bool
+	Size() int
+	Height() int
+	Print(w io.Writer)
+}
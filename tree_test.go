@@ -0,0 +1,33 @@
+package tree_test
+
+import (
+	"testing"
+
+	"github.com/pree-dew/tree"
+	"github.com/pree-dew/tree/ntree"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ tree.Tree[int, string] = (*ntree.Tree[int, string])(nil)
+
+// moveAll moves every listed key from src to dst using only the interface
+func moveAll[K comparable, V any](src, dst tree.Tree[K, V], keys []K) {
+	for _, key := range keys {
+		if value, found := src.Get(key); found {
+			dst.Put(key, value)
+			src.Remove(key)
+		}
+	}
+}
+
+func TestTreeInterface(t *testing.T) {
+	src, dst := ntree.New[int, string](3), ntree.New[int, string](4)
+	for key := 1; key <= 5; key++ {
+		src.Put(key, "v")
+	}
+
+	moveAll[int, string](src, dst, []int{2, 4, 6})
+	assert.Equal(t, 3, src.Size(), "moved keys should leave the source")
+	assert.Equal(t, 2, dst.Size(), "present keys should reach the destination")
+	assert.True(t, dst.Contains(4), "key 4 should have moved")
+}